@@ -0,0 +1,81 @@
+package parse
+
+import (
+	"reflect"
+)
+
+// Action constructs a RuleParseHook which computes a value of the Tree
+// with fn and stores it into Tree.Value, this way values are computed
+// bottom-up during the parse without a second pass over the Tree.
+// Values passed to fn are aligned with Tree.Childs,
+// childs without a value yield a zero value of T.
+// Values decoded by built-in rules (like string of StringLiteral
+// or Keywords) are passed if they have type T, otherwise they
+// yield a zero value of T too.
+// Child value of another type computed by Action is a programming
+// error which fails the whole parse with ErrFatal wrapping ErrValueType.
+//
+//	number := NewRegexp("number", "^[0-9]+", Action(
+//		func(ctx *Context, t *Tree, values []int) (int, error) {
+//			return strconv.Atoi(string(t.Data))
+//		},
+//	))
+func Action[T any](fn func(ctx *Context, t *Tree, values []T) (T, error)) RuleParseHook {
+	return func(ctx *Context, t *Tree) error {
+		values := make([]T, len(t.Childs))
+		for k, child := range t.Childs {
+			value, err := TreeValue[T](child)
+			if err != nil {
+				if !child.action {
+					continue // NOTE: value decoded by the built-in rule
+				}
+				return NewErrFatal(err)
+			}
+			values[k] = value
+		}
+		value, err := fn(ctx, t, values)
+		if err != nil {
+			return err
+		}
+		t.Value = value
+		t.action = true
+		return nil
+	}
+}
+
+// TreeValue returns Tree.Value as T.
+// Tree without a value yields a zero value of T.
+func TreeValue[T any](t *Tree) (T, error) {
+	var value T
+	if t.Value == nil {
+		return value, nil
+	}
+	value, ok := t.Value.(T)
+	if !ok {
+		return value, NewErrValueType(t, reflect.TypeOf(&value).Elem())
+	}
+	return value, nil
+}
+
+//
+
+// ParseValue parses the input like Parse does and
+// returns Tree.Value of the root Tree, see Action.
+func (p *Parser) ParseValue(r Rule, input []byte) (*Tree, interface{}, error) {
+	tree, err := p.Parse(r, input)
+	if tree == nil {
+		return nil, nil, err
+	}
+	return tree, tree.Value, err
+}
+
+// Eval parses the input with DefaultParser and
+// returns Tree.Value of the root Tree as T, see Action.
+func Eval[T any](rule Rule, input []byte) (T, error) {
+	var value T
+	tree, err := DefaultParser.Parse(rule, input)
+	if err != nil {
+		return value, err
+	}
+	return TreeValue[T](tree)
+}
@@ -0,0 +1,124 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAction(t *testing.T) {
+	var (
+		number = NewRegexp("number", "^[0-9]+", Action(
+			func(ctx *Context, tree *Tree, values []int) (int, error) {
+				return strconv.Atoi(string(tree.Data))
+			},
+		))
+		sum = NewSepBy1("sum", NewWrapper("term", number), NewTerminal("+", "+"), Action(
+			func(ctx *Context, tree *Tree, values []int) (int, error) {
+				result := 0
+				for _, value := range values {
+					result += value
+				}
+				return result, nil
+			},
+		))
+		value = NewEither("value", sum, NewTerminal("none", "none"))
+		list  = NewChain("list", NewTerminal("[", "["), NewOptional("items", value), NewTerminal("]", "]"))
+	)
+
+	samples := []struct {
+		text  string
+		rule  Rule
+		value interface{}
+	}{
+		{"1", number, 1},
+		{"1+2+39", sum, 42},
+		{"1+2", value, 3},
+		{"none", value, nil},
+		{"[1+2]", list, nil},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample)
+			tree, value, err := NewParser().ParseValue(sample.rule, []byte(sample.text))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, sample.value, value, msg)
+			assert.Equal(t, sample.value, tree.Value, msg)
+		})
+	}
+
+	tree, err := Parse(list, []byte("[1+2]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, tree.Childs[1].Value)
+
+	result, err := Eval[int](sum, []byte("20+22"))
+	assert.Nil(t, err)
+	assert.Equal(t, 42, result)
+
+	var typeErr *ErrValueType
+	_, err = Eval[string](sum, []byte("1"))
+	assert.True(t, errors.As(err, &typeErr))
+	assert.Equal(t, reflect.TypeOf(""), typeErr.Want)
+	assert.Equal(
+		t,
+		`Unexpected value type 'int' of 'sum' rule at "?:1:1", want 'string'`,
+		typeErr.Error(),
+	)
+
+	var (
+		keyword = NewKeywords("keyword", []string{"let"})
+		str     = NewStringLiteral("string", `"`)
+		values  []interface{}
+		let     = NewChain("let", keyword, NewTerminal(" ", " "), number, Action(
+			func(ctx *Context, tree *Tree, v []int) (int, error) {
+				values = append(values, v)
+				return v[2], nil
+			},
+		))
+		quoted = NewChain("quoted", NewWrapper("value", str), Action(
+			func(ctx *Context, tree *Tree, v []string) (string, error) {
+				values = append(values, v)
+				return v[0], nil
+			},
+		))
+	)
+	result, err = Eval[int](let, []byte("let 5"))
+	assert.Nil(t, err)
+	assert.Equal(t, 5, result)
+	text, err := Eval[string](quoted, []byte(`"a"`))
+	assert.Nil(t, err)
+	assert.Equal(t, "a", text)
+	assert.Equal(t, []interface{}{[]int{0, 0, 5}, []string{"a"}}, values)
+
+	var (
+		calls    int
+		mismatch = NewEither(
+			"mismatch",
+			NewChain("number as string", number, Action(
+				func(ctx *Context, tree *Tree, values []string) (string, error) {
+					return "", nil
+				},
+			)),
+			NewRegexp("fallback", "^[0-9]+", func(ctx *Context, tree *Tree) error {
+				calls++
+				return nil
+			}),
+		)
+		fatalErr *ErrFatal
+	)
+	_, err = Parse(mismatch, []byte("1"))
+	assert.True(t, errors.As(err, &fatalErr))
+	assert.True(t, errors.As(err, &typeErr))
+	assert.Equal(t, 1, typeErr.Tree.Value)
+	assert.Equal(t, reflect.TypeOf(""), typeErr.Want)
+	assert.Equal(t, 0, calls)
+}
@@ -0,0 +1,674 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ByteSet is a set of bytes, it is used to describe
+// which bytes could start a Rule match.
+type ByteSet [4]uint64
+
+// Add adds byte b into the set.
+func (s *ByteSet) Add(b byte) {
+	s[b>>6] |= 1 << (b & 63)
+}
+
+// AddRange adds bytes from lo to hi inclusive into the set.
+func (s *ByteSet) AddRange(lo byte, hi byte) {
+	for b := int(lo); b <= int(hi); b++ {
+		s.Add(byte(b))
+	}
+}
+
+// AddRune adds the first byte of the UTF-8 encoded rune c into the set.
+func (s *ByteSet) AddRune(c rune) {
+	s.AddRuneRange(c, c)
+}
+
+// AddRuneRange adds first bytes of UTF-8 encoded runes
+// from lo to hi inclusive into the set.
+// If range contains utf8.RuneError then all non-ASCII bytes are added,
+// because invalid UTF-8 (including truncated sequences)
+// is decoded as utf8.RuneError.
+func (s *ByteSet) AddRuneRange(lo rune, hi rune) {
+	if lo <= utf8.RuneError && utf8.RuneError <= hi {
+		s.AddRange(utf8.RuneSelf, 0xff)
+	}
+	if lo < utf8.RuneSelf {
+		top := hi
+		if top >= utf8.RuneSelf {
+			top = utf8.RuneSelf - 1
+		}
+		s.AddRange(byte(lo), byte(top))
+		lo = utf8.RuneSelf
+	}
+	if hi < lo {
+		return
+	}
+	var buf [utf8.UTFMax]byte
+	utf8.EncodeRune(buf[:], lo)
+	first := buf[0]
+	utf8.EncodeRune(buf[:], hi)
+	s.AddRange(first, buf[0])
+}
+
+// Has returns true if byte b is in the set.
+func (s ByteSet) Has(b byte) bool {
+	return s[b>>6]&(1<<(b&63)) != 0
+}
+
+// Union adds all bytes from other set into this set,
+// returns true if set was changed.
+func (s *ByteSet) Union(other ByteSet) bool {
+	changed := false
+	for k := range s {
+		v := s[k] | other[k]
+		if v != s[k] {
+			s[k] = v
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Len returns number of bytes in the set.
+func (s ByteSet) Len() int {
+	n := 0
+	for _, v := range s {
+		n += bits.OnesCount64(v)
+	}
+	return n
+}
+
+// IsFull returns true if set contains every byte.
+func (s ByteSet) IsFull() bool {
+	return s.Len() == 256
+}
+
+// String returns a set representation as a regexp-like class.
+func (s ByteSet) String() string {
+	buf := &strings.Builder{}
+	buf.WriteString("[")
+	for b := 0; b < 256; b++ {
+		if !s.Has(byte(b)) {
+			continue
+		}
+		end := b
+		for end < 255 && s.Has(byte(end+1)) {
+			end++
+		}
+		buf.WriteString(byteSetQuote(byte(b)))
+		if end > b+1 {
+			buf.WriteString("-")
+		}
+		if end > b {
+			buf.WriteString(byteSetQuote(byte(end)))
+		}
+		b = end
+	}
+	buf.WriteString("]")
+	return buf.String()
+}
+
+// byteSetQuote quotes byte b to use inside a class.
+func byteSetQuote(b byte) string {
+	switch {
+	case strings.IndexByte(`\-[]^`, b) >= 0:
+		return `\` + string(b)
+	case b > 0x20 && b < 0x7f:
+		return string(b)
+	default:
+		return fmt.Sprintf(`\x%02x`, b)
+	}
+}
+
+// NewByteSetFull constructs a ByteSet with every byte.
+func NewByteSetFull() ByteSet {
+	return ByteSet{^uint64(0), ^uint64(0), ^uint64(0), ^uint64(0)}
+}
+
+//
+
+// Analysis is a static analysis of the Rule graph.
+// It computes whether each rule could match an empty input (nullable),
+// the set of bytes which could start the rule match (FIRST)
+// and the set of bytes which could follow the rule match (FOLLOW).
+//
+// Sets are conservative: rules which could not be analyzed
+// (like custom rules or Regexp which is not anchored with `^`)
+// are nullable and could start with any byte.
+// Errors do not assume unknown rules are nullable.
+// Lookahead predicates consume nothing, so they are nullable
+// with empty FIRST set.
+type Analysis struct {
+	Root  Rule
+	Rules Rules
+
+	strict    bool          // unknown rules are not nullable
+	certain   map[Rule]bool // nullable computed with strict analysis
+	nullable  map[Rule]bool
+	first     map[Rule]*ByteSet
+	follow    map[Rule]*ByteSet
+	followEOF map[Rule]bool
+}
+
+// Nullable returns true if rule could match an empty input.
+// Unknown rules are nullable.
+func (a *Analysis) Nullable(rule Rule) bool {
+	if _, ok := a.first[rule]; !ok {
+		return true
+	}
+	return a.nullable[rule]
+}
+
+// First returns a set of bytes which could start the rule match.
+// Unknown rules could start with any byte.
+func (a *Analysis) First(rule Rule) ByteSet {
+	first, ok := a.first[rule]
+	if !ok {
+		return NewByteSetFull()
+	}
+	return *first
+}
+
+// Follow returns a set of bytes which could follow the rule match
+// and true if rule could be followed by the end of input.
+// Unknown rules could be followed by anything.
+func (a *Analysis) Follow(rule Rule) (ByteSet, bool) {
+	follow, ok := a.follow[rule]
+	if !ok {
+		return NewByteSetFull(), true
+	}
+	return *follow, a.followEOF[rule]
+}
+
+// Errors returns rules which could behave incorrectly,
+// like Repetition of the nullable rule which could repeat forever.
+func (a *Analysis) Errors() error {
+	errs := []error{}
+	for _, rule := range a.Rules {
+		switch r := rule.(type) {
+		case *Repetition:
+			if r.Variadic && r.Rule != nil && a.certain[r.Rule] {
+				errs = append(errs, NewErrRepetitionNullable(r))
+			}
+		case *SepBy:
+			if r.Rule != nil && r.Separator != nil && a.certain[r.Rule] && a.certain[r.Separator] {
+				errs = append(errs, NewErrRepetitionNullable(r))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// expected returns finite rules which fail at the current position
+// when the rule could not start with the next input byte, in the order
+// they are tried, so Either could report alternatives skipped
+// by the prediction as expected.
+func (a *Analysis) expected(rule Rule) Rules {
+	var (
+		rules = Rules{}
+		seen  = map[Rule]bool{}
+		walk  func(rule Rule)
+	)
+	sequence := func(rules ...Rule) {
+		for _, rule := range rules {
+			walk(rule)
+			if rule == nil || !a.Nullable(rule) {
+				return
+			}
+		}
+	}
+	walk = func(rule Rule) {
+		if rule == nil || seen[rule] {
+			return
+		}
+		seen[rule] = true
+		if rule.IsFinite() {
+			rules = append(rules, rule)
+			return
+		}
+		switch r := rule.(type) {
+		case *Chain:
+			sequence(r.Rules...)
+		case *Either:
+			for _, sub := range r.Rules {
+				walk(sub)
+			}
+		case *Repetition:
+			walk(r.Rule)
+		case *Optional:
+			walk(r.Rule)
+		case *SepBy:
+			sequence(r.Rule, r.Separator)
+		case *Bound:
+			sequence(r.Open, r.Rule, r.Close)
+		case *Expression:
+			for _, operator := range r.Operators {
+				if operator.Kind == ExpressionPrefix {
+					walk(operator.Rule)
+				}
+			}
+			walk(r.Operand)
+		case *Wrapper:
+			walk(r.Rule)
+		case *Ref:
+			walk(r.Target)
+		}
+	}
+	walk(rule)
+	return rules
+}
+
+// analyze computes nullable & FIRST sets for all rules.
+func (a *Analysis) analyze() {
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range a.Rules {
+			first, nullable := a.rule(rule)
+			if a.first[rule].Union(first) {
+				changed = true
+			}
+			if nullable && !a.nullable[rule] {
+				a.nullable[rule] = true
+				changed = true
+			}
+		}
+	}
+}
+
+// rule computes nullable & FIRST set of the rule
+// using current state of the analysis for its childs.
+func (a *Analysis) rule(rule Rule) (ByteSet, bool) {
+	var first ByteSet
+	switch r := rule.(type) {
+	case *Terminal:
+		return analysisLiteral(string(r.Value), r.Fold), len(r.Value) == 0
+	case *Keywords:
+		for _, keyword := range r.Keywords() {
+			first.Union(analysisLiteral(keyword, r.Fold))
+		}
+		return first, false
+	case *StringLiteral:
+		for _, c := range r.Quotes {
+			first.AddRune(c)
+		}
+		return first, false
+	case *RuneClass:
+		if r.Negate {
+			return NewByteSetFull(), false
+		}
+		for _, table := range r.Tables {
+			for _, rng := range table.R16 {
+				first.AddRuneRange(rune(rng.Lo), rune(rng.Hi))
+			}
+			for _, rng := range table.R32 {
+				first.AddRuneRange(rune(rng.Lo), rune(rng.Hi))
+			}
+		}
+		return first, false
+	case *Regexp:
+		first, nullable, ok := analysisRegexp(r)
+		if !ok {
+			return a.unknown()
+		}
+		return first, nullable
+	case *Chain:
+		return a.sequence(r.Rules...)
+	case *Either:
+		nullable := false
+		for _, sub := range r.Rules {
+			first.Union(a.get(sub))
+			nullable = nullable || a.nullable[sub]
+		}
+		return first, nullable
+	case *Repetition:
+		return a.get(r.Rule), r.Times == 0 || a.nullable[r.Rule]
+	case *Optional:
+		return a.get(r.Rule), true
+	case *SepBy:
+		first = a.get(r.Rule)
+		if a.nullable[r.Rule] {
+			first.Union(a.get(r.Separator))
+		}
+		return first, r.Min == 0 || a.nullable[r.Rule]
+	case *Bound:
+		if r.Rule == nil {
+			first, nullable := a.sequence(r.Open)
+			if nullable {
+				return NewByteSetFull(), true
+			}
+			return first, false
+		}
+		return a.sequence(r.Open, r.Rule, r.Close)
+	case *Expression:
+		first = a.get(r.Operand)
+		for _, operator := range r.Operators {
+			if operator.Kind == ExpressionPrefix {
+				first.Union(a.get(operator.Rule))
+			}
+		}
+		return first, a.nullable[r.Operand]
+	case *Not, *And:
+		return first, true
+	case *Wrapper:
+		return a.get(r.Rule), a.nullable[r.Rule]
+	case *Ref:
+		return a.get(r.Target), a.nullable[r.Target]
+	default:
+		return a.unknown()
+	}
+}
+
+// unknown returns FIRST set & nullable of the rule which could not be analyzed.
+func (a *Analysis) unknown() (ByteSet, bool) {
+	return NewByteSetFull(), !a.strict
+}
+
+// get returns current FIRST set of the rule.
+func (a *Analysis) get(rule Rule) ByteSet {
+	if first, ok := a.first[rule]; ok {
+		return *first
+	}
+	return ByteSet{}
+}
+
+// sequence computes nullable & FIRST set of the rules matched one after another.
+func (a *Analysis) sequence(rules ...Rule) (ByteSet, bool) {
+	var first ByteSet
+	for _, rule := range rules {
+		first.Union(a.get(rule))
+		if rule == nil || !a.nullable[rule] {
+			return first, false
+		}
+	}
+	return first, true
+}
+
+// analyzeFollow computes FOLLOW sets for all rules.
+func (a *Analysis) analyzeFollow() {
+	a.followEOF[a.Root] = true
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range a.Rules {
+			if a.followRule(rule) {
+				changed = true
+			}
+		}
+	}
+}
+
+// followRule propagates FOLLOW set of the rule into its childs,
+// returns true if some set was changed.
+func (a *Analysis) followRule(rule Rule) bool {
+	var (
+		follow  = *a.follow[rule]
+		eof     = a.followEOF[rule]
+		changed = false
+	)
+	switch r := rule.(type) {
+	case *Chain:
+		changed = a.followSequence(follow, eof, r.Rules...)
+	case *Either:
+		for _, sub := range r.Rules {
+			changed = a.propagate(sub, follow, eof) || changed
+		}
+	case *Repetition:
+		set := a.get(r.Rule)
+		set.Union(follow)
+		changed = a.propagate(r.Rule, set, eof)
+	case *SepBy:
+		set := a.get(r.Separator)
+		set.Union(follow)
+		changed = a.propagate(r.Rule, set, eof)
+
+		set = a.get(r.Rule)
+		tail := r.Trailing != SepByTrailingForbid || a.nullable[r.Rule]
+		if tail {
+			set.Union(follow)
+		}
+		changed = a.propagate(r.Separator, set, tail && eof) || changed
+	case *Bound:
+		if r.Rule == nil {
+			changed = a.propagate(r.Open, NewByteSetFull(), false)
+			changed = a.propagate(r.Close, follow, eof) || changed
+		} else {
+			changed = a.followSequence(follow, eof, r.Open, r.Rule, r.Close)
+		}
+	case *Expression:
+		var operands, operators ByteSet
+		operands = a.get(r.Operand)
+		for _, operator := range r.Operators {
+			switch operator.Kind {
+			case ExpressionPrefix:
+				operands.Union(a.get(operator.Rule))
+			default:
+				operators.Union(a.get(operator.Rule))
+				operators.Union(a.get(operator.Else))
+			}
+		}
+		operators.Union(follow)
+		changed = a.propagate(r.Operand, operators, eof)
+		for _, operator := range r.Operators {
+			if operator.Kind == ExpressionPostfix {
+				changed = a.propagate(operator.Rule, operators, eof) || changed
+				continue
+			}
+			changed = a.propagate(operator.Rule, operands, false) || changed
+			changed = a.propagate(operator.Else, operands, false) || changed
+		}
+	case *Not:
+		changed = a.propagate(r.Rule, NewByteSetFull(), true)
+	case *And:
+		changed = a.propagate(r.Rule, NewByteSetFull(), true)
+	case *Optional:
+		changed = a.propagate(r.Rule, follow, eof)
+	case *Wrapper:
+		changed = a.propagate(r.Rule, follow, eof)
+	case *Ref:
+		changed = a.propagate(r.Target, follow, eof)
+	default:
+		for _, child := range rule.GetChilds() {
+			sub, _ := child.(Rule)
+			changed = a.propagate(sub, NewByteSetFull(), true) || changed
+		}
+	}
+	return changed
+}
+
+// followSequence propagates FOLLOW sets into the rules
+// matched one after another which are followed by follow set.
+func (a *Analysis) followSequence(follow ByteSet, eof bool, rules ...Rule) bool {
+	changed := false
+	for k := len(rules) - 1; k >= 0; k-- {
+		rule := rules[k]
+		changed = a.propagate(rule, follow, eof) || changed
+		if rule == nil {
+			continue
+		}
+		if !a.nullable[rule] {
+			follow, eof = ByteSet{}, false
+		}
+		follow.Union(a.get(rule))
+	}
+	return changed
+}
+
+// propagate adds set & eof into FOLLOW of the rule,
+// returns true if it was changed.
+func (a *Analysis) propagate(rule Rule, set ByteSet, eof bool) bool {
+	follow, ok := a.follow[rule]
+	if !ok {
+		return false
+	}
+	changed := follow.Union(set)
+	if eof && !a.followEOF[rule] {
+		a.followEOF[rule] = true
+		changed = true
+	}
+	return changed
+}
+
+//
+
+// Analyze computes the Analysis for the Rule graph starting from root.
+// Analysis is a snapshot, it should be recomputed if rules are changed.
+func Analyze(root Rule) *Analysis {
+	a := &Analysis{
+		Root:      root,
+		Rules:     Rules{},
+		nullable:  map[Rule]bool{},
+		first:     map[Rule]*ByteSet{},
+		follow:    map[Rule]*ByteSet{},
+		followEOF: map[Rule]bool{},
+	}
+	grammarWalk(Rules{root}, func(rule Rule, parent Rule) {
+		if rule == nil {
+			return
+		}
+		a.Rules = append(a.Rules, rule)
+		a.first[rule] = &ByteSet{}
+		a.follow[rule] = &ByteSet{}
+	})
+	a.analyze()
+	a.analyzeFollow()
+
+	strict := &Analysis{
+		Root:     root,
+		Rules:    a.Rules,
+		strict:   true,
+		nullable: map[Rule]bool{},
+		first:    map[Rule]*ByteSet{},
+	}
+	for _, rule := range a.Rules {
+		strict.first[rule] = &ByteSet{}
+	}
+	strict.analyze()
+	a.certain = strict.nullable
+	return a
+}
+
+// analysisLiteral returns FIRST set of the literal.
+func analysisLiteral(literal string, fold bool) ByteSet {
+	var first ByteSet
+	if literal == "" {
+		return first
+	}
+	c, size := utf8.DecodeRuneInString(literal)
+	if c == utf8.RuneError && size == 1 {
+		first.Add(literal[0]) // NOTE: literal is not a valid UTF-8
+		return first
+	}
+	first.AddRune(c)
+	if fold {
+		for f := unicode.SimpleFold(c); f != c; f = unicode.SimpleFold(f) {
+			first.AddRune(f)
+		}
+	}
+	return first
+}
+
+// analysisRegexp returns FIRST set & nullable of the regexp,
+// only regexps anchored to the current position are analyzed.
+// It returns false if regexp could not be analyzed.
+func analysisRegexp(r *Regexp) (ByteSet, bool, bool) {
+	if r.Regexp == nil {
+		return ByteSet{}, false, false
+	}
+	re, err := syntax.Parse(r.Expr, syntax.Perl)
+	if err != nil {
+		return ByteSet{}, false, false
+	}
+	re = re.Simplify()
+	if !analysisRegexpAnchored(re) {
+		return ByteSet{}, false, false
+	}
+	first, nullable := analysisRegexpSyntax(re)
+	return first, nullable, true
+}
+
+// analysisRegexpAnchored returns true if every top-level
+// alternative of the regexp starts with `^` (beginning of text).
+func analysisRegexpAnchored(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpBeginText:
+		return true
+	case syntax.OpCapture:
+		return analysisRegexpAnchored(re.Sub[0])
+	case syntax.OpConcat:
+		return len(re.Sub) > 0 && analysisRegexpAnchored(re.Sub[0])
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if !analysisRegexpAnchored(sub) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// analysisRegexpSyntax returns FIRST set & nullable of the regexp syntax tree.
+func analysisRegexpSyntax(re *syntax.Regexp) (ByteSet, bool) {
+	var first ByteSet
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine,
+		syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return first, true
+	case syntax.OpNoMatch:
+		return first, false
+	case syntax.OpAnyChar:
+		return NewByteSetFull(), false
+	case syntax.OpAnyCharNotNL:
+		first = NewByteSetFull()
+		first[0] &^= 1 << '\n'
+		return first, false
+	case syntax.OpLiteral:
+		if len(re.Rune) == 0 {
+			return first, true
+		}
+		return analysisLiteral(string(re.Rune[:1]), re.Flags&syntax.FoldCase != 0), false
+	case syntax.OpCharClass:
+		for k := 0; k+1 < len(re.Rune); k += 2 {
+			first.AddRuneRange(re.Rune[k], re.Rune[k+1])
+		}
+		return first, false
+	case syntax.OpCapture:
+		return analysisRegexpSyntax(re.Sub[0])
+	case syntax.OpStar, syntax.OpQuest:
+		first, _ = analysisRegexpSyntax(re.Sub[0])
+		return first, true
+	case syntax.OpPlus:
+		return analysisRegexpSyntax(re.Sub[0])
+	case syntax.OpRepeat:
+		first, nullable := analysisRegexpSyntax(re.Sub[0])
+		return first, nullable || re.Min == 0
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			set, nullable := analysisRegexpSyntax(sub)
+			first.Union(set)
+			if !nullable {
+				return first, false
+			}
+		}
+		return first, true
+	case syntax.OpAlternate:
+		nullable := false
+		for _, sub := range re.Sub {
+			set, subNullable := analysisRegexpSyntax(sub)
+			first.Union(set)
+			nullable = nullable || subNullable
+		}
+		return first, nullable
+	default:
+		return NewByteSetFull(), true
+	}
+}
@@ -0,0 +1,191 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"unicode"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteSet(t *testing.T) {
+	var s ByteSet
+	assert.Equal(t, "[]", s.String())
+
+	s.AddRange('a', 'z')
+	s.Add('-')
+	s.Add('0')
+	s.Add('1')
+	s.Add('\n')
+	assert.True(t, s.Has('q'))
+	assert.False(t, s.Has('A'))
+	assert.Equal(t, 30, s.Len())
+	assert.Equal(t, `[\x0a\-01a-z]`, s.String())
+
+	var o ByteSet
+	o.AddRune('ж')
+	assert.Equal(t, `[\xd0]`, o.String())
+	assert.True(t, s.Union(o))
+	assert.False(t, s.Union(o))
+
+	var invalid ByteSet
+	invalid.AddRuneRange(0x100, unicode.MaxRune)
+	assert.True(t, invalid.Has(0xff))
+	assert.True(t, invalid.Has(0x80))
+	assert.False(t, invalid.Has('a'))
+
+	assert.True(t, NewByteSetFull().IsFull())
+	assert.Equal(t, `[\x00-\xff]`, NewByteSetFull().String())
+}
+
+func TestAnalyze(t *testing.T) {
+	var (
+		number  = NewRegexp("number", "^[0-9]+")
+		ident   = NewRuneRange("ident", 'a', 'z')
+		minus   = NewTerminal("-", "-")
+		plus    = NewTerminal("+", "+")
+		comma   = NewTerminal(",", ",")
+		keyword = NewKeywordsFold("keyword", []string{"let", "Var"})
+		str     = NewStringLiteral("string", `"'`)
+		sign    = NewOptional("sign", minus)
+		signed  = NewChain("signed", sign, number)
+		value   = NewEither("value", signed, ident, keyword, str)
+		list    = NewSepBy("list", value, comma)
+		sum     = NewChain("sum", list, NewRepetitionTimesVariadic("tail", 0, NewChain("plus list", plus, list)))
+		any     = NewRegexp("any", ".*")
+		ahead   = NewNot("not", minus)
+	)
+
+	a := Analyze(sum)
+
+	samples := []struct {
+		rule      Rule
+		nullable  bool
+		first     string
+		follow    string
+		followEOF bool
+	}{
+		{number, false, `[0-9]`, `[+,]`, true},
+		{minus, false, `[\-]`, `[0-9]`, false},
+		{sign, true, `[\-]`, `[0-9]`, false},
+		{signed, false, `[\-0-9]`, `[+,]`, true},
+		{keyword, false, `[LVlv]`, `[+,]`, true},
+		{str, false, `["']`, `[+,]`, true},
+		{value, false, `["'\-0-9LVa-z]`, `[+,]`, true},
+		{comma, false, `[,]`, `["'\-0-9LVa-z]`, false},
+		{list, true, `["'\-0-9LVa-z]`, `[+]`, true},
+		{plus, false, `[+]`, `["'+\-0-9LVa-z]`, true},
+		{sum, true, `["'+\-0-9LVa-z]`, `[]`, true},
+		{any, true, `[\x00-\xff]`, `[]`, true},
+		{ahead, true, `[]`, `[]`, true},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			a := a
+			if sample.rule == any || sample.rule == ahead {
+				a = Analyze(sample.rule)
+			}
+			msg := spew.Sdump(k, sample.rule.Name())
+			follow, eof := a.Follow(sample.rule)
+			assert.Equal(t, sample.nullable, a.Nullable(sample.rule), msg)
+			assert.Equal(t, sample.first, a.First(sample.rule).String(), msg)
+			assert.Equal(t, sample.follow, follow.String(), msg)
+			assert.Equal(t, sample.followEOF, eof, msg)
+		})
+	}
+	assert.Nil(t, a.Errors())
+}
+
+func TestAnalyzeRecursive(t *testing.T) {
+	term := NewRegexp("term", "^(?i:x)|^[0-9]")
+	expr := NewEither("expr")
+	expr.Add(
+		NewChain("add", expr, NewTerminal("+", "+"), term),
+		term,
+	)
+	a := Analyze(expr)
+	assert.False(t, a.Nullable(expr))
+	assert.Equal(t, `[0-9Xx]`, a.First(expr).String())
+
+	follow, eof := a.Follow(expr)
+	assert.Equal(t, `[+]`, follow.String())
+	assert.True(t, eof)
+}
+
+func TestAnalyzeErrors(t *testing.T) {
+	var (
+		nullable = NewOptional("optional", NewTerminal("x", "x"))
+		many     = NewRepetitionTimesVariadic("many", 0, nullable)
+		times    = NewRepetitionTimes("times", 2, nullable)
+		list     = NewSepBy("list", nullable, NewRegexp("spaces", "^ *"))
+	)
+	err := Analyze(NewChain("root", many, times, list)).Errors()
+
+	errs := err.(interface{ Unwrap() []error }).Unwrap()
+	assert.Equal(t, []error{NewErrRepetitionNullable(many), NewErrRepetitionNullable(list)}, errs)
+
+	// NOTE: rules which could not be analyzed are not assumed nullable
+	assert.Nil(t, Analyze(NewRepetition("any", NewRegexp("any", "^."))).Errors())
+	assert.Nil(t, Analyze(NewRepetition("lines", NewRegexp("line", "^.+\n"))).Errors())
+	assert.Nil(t, Analyze(NewRepetition("customs", newTestRuleFinite("custom"))).Errors())
+	assert.Nil(t, Analyze(NewRepetition("unanchored", NewRegexp("unanchored", "x*"))).Errors())
+
+	var nullableErr *ErrRepetitionNullable
+	assert.True(t, errors.As(err, &nullableErr))
+	assert.Equal(
+		t,
+		"Rule '*parse.Repetition' = 'many' repeats a rule which could match an empty input",
+		nullableErr.Error(),
+	)
+}
+
+func TestParserPredict(t *testing.T) {
+	var (
+		number = NewRegexp("number", "^[0-9]+")
+		group  = NewChain("group", NewTerminal("(", "("), number, NewTerminal(")", ")"))
+		// undefined reference fails with ErrRuleUndefined if it is tried,
+		// its FIRST set is empty, so it is skipped with prediction
+		value = NewEither("value", NewRef("undefined"), group, number)
+		list  = NewSepBy1("list", value, NewTerminal(",", ","))
+	)
+
+	_, err := NewParser().Parse(list, []byte("(1),2"))
+	assert.Equal(t, NewErrRuleUndefined("undefined"), err)
+
+	parser := NewParser(ParserOptionPredict(true))
+	tree, err := parser.Parse(list, []byte("(1),2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, tree.Childs, 2)
+	assert.Equal(t, []byte("(1),2"), tree.Data)
+
+	_, err = parser.Parse(list, []byte("1,?"))
+	assert.IsType(t, &ErrUnexpectedToken{}, err)
+}
+
+// TestParserPredictSame checks prediction does not change parse results.
+func TestParserPredictSame(t *testing.T) {
+	samples := []struct {
+		rule  Rule
+		input []byte
+	}{
+		{NewEither("value", NewRegexp("not a", "^[^a]"), NewTerminal("z", "z")), []byte{0xff}},
+		{NewEither("value", NewRegexp("not a", "^[^a]+"), NewTerminal("z", "z")), []byte{0xe2, 'b'}},
+		{NewEither("value", NewTerminal("invalid", "\xff"), NewTerminal("z", "z")), []byte{0xff}},
+		{NewEither("value", NewRegexp("partially anchored", "^a|b"), NewTerminal("z", "z")), []byte("qxb")},
+		{NewEither("value", NewRegexp("any", "^.+"), NewTerminal("z", "z")), []byte("q")},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample.input)
+			tree, err := NewParser().Parse(sample.rule, sample.input)
+			predicted, predictedErr := NewParser(ParserOptionPredict(true)).Parse(sample.rule, sample.input)
+			assert.Nil(t, err, msg)
+			assert.Equal(t, err, predictedErr, msg)
+			assert.Equal(t, tree, predicted, msg)
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package parse
+
+import (
+	"unicode/utf8"
+)
+
+var _ Rule = new(Bound)
+
+// Bound is a Rule which matches a delimited part of the input,
+// like parentheses, block comments or quoted blocks.
+// It matches Open, then inner Rule, then Close.
+// Once Open is matched missing Close is reported as ErrBoundIncomplete
+// pointing to the location of the Open, instead of an error
+// somewhere deep inside the inner Rule.
+//
+// When inner Rule is nil the body is scanned until the Close,
+// if Nested is set then nested Open & Close pairs are skipped
+// while scanning, this is useful for nested block comments.
+type Bound struct {
+	name       string
+	Open       Rule
+	Rule       Rule
+	Close      Rule
+	Nested     bool
+	Hooks      []RuleParseHook
+	EnterHooks []RuleEnterHook
+}
+
+// Name indicates the name which was given to the rule
+// on creation. Name could be not unique.
+func (r *Bound) Name() string {
+	return r.name
+}
+
+// Show this node as a string.
+// You should provide childs as string
+// to this function, it does not care
+// about nesting in a tree, it only shows
+// string representation of itself.
+func (r *Bound) Show(childs string) string {
+	return RuleShow(
+		r,
+		r.GetParameters().String(),
+		childs,
+	)
+}
+
+// String returns rule as a string,
+// resolving recursion with `<circular>` placeholder.
+func (r *Bound) String() string {
+	return TreerString(r)
+}
+
+// GetChilds returns a slice of Rule which is
+// children for current Rule.
+func (r *Bound) GetChilds() Treers {
+	if r.Rule == nil {
+		return Treers{r.Open, r.Close}
+	}
+	return Treers{r.Open, r.Rule, r.Close}
+}
+
+//
+
+// GetParameters returns a KV rule parameters.
+func (r *Bound) GetParameters() RuleParameters {
+	return RuleParameters{
+		"name":   r.name,
+		"nested": r.Nested,
+	}
+}
+
+// IsFinite returns true if this rule is
+// not a wrapper for other rules.
+func (r *Bound) IsFinite() bool {
+	return false
+}
+
+// Parse consumes some bytes from input & emits a Tree
+// using settings defined during creation of the concrete Rule type.
+// May return an error if something goes wrong, should provide some
+// location information to the user which points to position in input.
+func (r *Bound) Parse(ctx *Context, input []byte) (*Tree, error) {
+	if err := runEnterHooks(ctx, r, input, r.EnterHooks); err != nil {
+		return nil, err
+	}
+
+	if r.Open == nil || r.Close == nil {
+		return nil, NewErrEmptyRule(r, ctx.Rule)
+	}
+
+	nextDepth := ctx.Depth + 1
+	if nextDepth > ctx.Parser.MaxDepth {
+		return nil, NewErrNestingTooDeep(ctx.Location, nextDepth)
+	}
+
+	var (
+		pos      = ctx.Location.Position
+		subTrees = make([]*Tree, 0, 3)
+		subTree  *Tree
+		err      error
+	)
+
+	open, err := r.parse(ctx, r.Open, pos, input)
+	if err != nil {
+		return nil, err
+	}
+	subTrees = append(subTrees, open)
+	pos = open.Region.End
+
+	if r.Rule == nil {
+		pos, err = r.scan(ctx, open, pos, input)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		subTree, err = r.parse(ctx, r.Rule, pos, input)
+		switch {
+		case err == ErrSkipRule:
+		case err != nil:
+			if r.isIncomplete(ctx, input, err) {
+				return nil, r.incomplete(open)
+			}
+			return nil, err
+		default:
+			subTrees = append(subTrees, subTree)
+			pos = subTree.Region.End
+		}
+	}
+
+	subTree, err = r.parse(ctx, r.Close, pos, input)
+	if err != nil {
+		// NOTE: bound is incomplete only if input is over,
+		// otherwise it is an unexpected token which gives
+		// other alternatives a chance to match
+		if r.isIncomplete(ctx, input, err) {
+			return nil, r.incomplete(open)
+		}
+		return nil, err
+	}
+	subTrees = append(subTrees, subTree)
+
+	region := TreeRegion(subTrees...)
+	line, col := ctx.Parser.Locate(ctx.Location.Position)
+	tree := &Tree{
+		Rule: r,
+		Location: &Location{
+			Path:     ctx.Location.Path,
+			Position: ctx.Location.Position,
+			Line:     line,
+			Column:   col,
+		},
+		Region: region,
+		Depth:  ctx.Depth,
+		Childs: subTrees,
+		Data:   input[:region.End-region.Start],
+	}
+	if err := runHooks(ctx, r, tree, r.Hooks); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// parse applies sub-rule at the position pos.
+func (r *Bound) parse(ctx *Context, rule Rule, pos int, input []byte) (*Tree, error) {
+	line, col := ctx.Parser.Locate(pos)
+	return ctx.Parser.ParseRule(
+		rule,
+		&Context{
+			Rule:   r,
+			Parser: ctx.Parser,
+			Location: &Location{
+				Path:     ctx.Location.Path,
+				Position: pos,
+				Line:     line,
+				Column:   col,
+			},
+			Depth: ctx.Depth + 1,
+			State: ctx.State,
+		},
+		input[pos-ctx.Location.Position:],
+	)
+}
+
+// scan skips the body of the bound until Close (respecting nesting if enabled),
+// returning position of the Close.
+func (r *Bound) scan(ctx *Context, open *Tree, pos int, input []byte) (int, error) {
+	var (
+		offset = ctx.Location.Position
+		depth  = 0
+		t      *Tree
+		err    error
+	)
+	for pos-offset < len(input) {
+		if r.Nested {
+			t, err = r.parse(ctx, r.Open, pos, input)
+			if err == nil && t.Region.End > pos {
+				depth++
+				pos = t.Region.End
+				continue
+			}
+		}
+
+		t, err = r.parse(ctx, r.Close, pos, input)
+		if err == nil && t.Region.End > pos {
+			if depth == 0 {
+				return pos, nil
+			}
+			depth--
+			pos = t.Region.End
+			continue
+		}
+
+		_, size := utf8.DecodeRune(input[pos-offset:])
+		pos += size
+	}
+	return pos, r.incomplete(open)
+}
+
+// incomplete constructs ErrBoundIncomplete for the Open tree.
+// isIncomplete returns true if err was caused by the end of input,
+// which means the bound is not closed.
+// Rules like Regexp fail at the end of input with ErrUnexpectedToken,
+// so error position is checked too.
+func (r *Bound) isIncomplete(ctx *Context, input []byte, err error) bool {
+	if isErrUnexpectedEOF(err) {
+		return true
+	}
+	loc := ErrorLocation(err)
+	return loc != nil && loc.Position >= ctx.Location.Position+len(input)
+}
+
+func (r *Bound) incomplete(open *Tree) error {
+	var closing []byte
+	if terminal, ok := r.Close.(*Terminal); ok {
+		closing = terminal.Value
+	} else {
+		closing = []byte(r.Close.Name())
+	}
+	return NewErrBoundIncomplete(open.Data, closing, open.Location)
+}
+
+//
+
+// NewBound constructs new *Bound.
+// Inner rule could be nil, in this case body is scanned until close.
+func NewBound(name string, open Rule, rule Rule, close Rule, hooks ...RuleParseHook) *Bound {
+	return &Bound{
+		name:  name,
+		Open:  open,
+		Rule:  rule,
+		Close: close,
+		Hooks: hooks,
+	}
+}
+
+// NewBoundNested constructs new *Bound which scans the body
+// until close, skipping nested open & close pairs.
+func NewBoundNested(name string, open Rule, close Rule, hooks ...RuleParseHook) *Bound {
+	return &Bound{
+		name:   name,
+		Open:   open,
+		Close:  close,
+		Nested: true,
+		Hooks:  hooks,
+	}
+}
+
+//
+
+// isErrUnexpectedEOF returns true if err is ErrUnexpectedEOF
+// or it was caused by ErrUnexpectedEOF.
+func isErrUnexpectedEOF(err error) bool {
+	switch e := err.(type) {
+	case *ErrUnexpectedEOF:
+		return true
+	case *ErrUnexpectedToken:
+		for _, inner := range e.Inner {
+			if isErrUnexpectedEOF(inner) {
+				return true
+			}
+		}
+	}
+	return false
+}
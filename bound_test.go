@@ -0,0 +1,253 @@
+package parse
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoundShow(t *testing.T) {
+	samples := []struct {
+		rule   Rule
+		show   string
+		childs Treers
+	}{
+		{
+			NewBound(
+				"parens",
+				NewTerminal("(", "("),
+				NewTerminal("x", "x"),
+				NewTerminal(")", ")"),
+			),
+			"*parse.Bound(name: parens, nested: false)(none)",
+			Treers{
+				NewTerminal("(", "("),
+				NewTerminal("x", "x"),
+				NewTerminal(")", ")"),
+			},
+		},
+		{
+			NewBoundNested(
+				"comment",
+				NewTerminal("open", "/*"),
+				NewTerminal("close", "*/"),
+			),
+			"*parse.Bound(name: comment, nested: true)(none)",
+			Treers{
+				NewTerminal("open", "/*"),
+				NewTerminal("close", "*/"),
+			},
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample)
+			assert.EqualValues(t, sample.show, sample.rule.Show("none"), msg)
+			assert.EqualValues(t, sample.childs, sample.rule.GetChilds(), msg)
+			assert.False(t, sample.rule.IsFinite(), msg)
+		})
+	}
+}
+
+func TestBound(t *testing.T) {
+	number := NewRegexp("number", "^[0-9]+")
+	expression := NewEither("expression")
+	parens := NewBound(
+		"parens",
+		NewTerminal("(", "("),
+		NewChain(
+			"sum",
+			expression,
+			NewRepetitionTimesVariadic(
+				"tail",
+				0,
+				NewChain("plus number", NewTerminal("+", "+"), expression),
+			),
+		),
+		NewTerminal(")", ")"),
+	)
+	expression.Add(number, parens)
+
+	tuple := NewEither(
+		"tuple or parens",
+		parens,
+		NewChain(
+			"tuple",
+			NewTerminal("(", "("),
+			NewSepBy1("items", number, NewTerminal(",", ",")),
+			NewTerminal(")", ")"),
+		),
+	)
+
+	addition := NewBound(
+		"addition",
+		NewTerminal("(", "("),
+		NewChain("sum", number, NewTerminal("+", "+"), number),
+		NewTerminal(")", ")"),
+	)
+	args := NewBound(
+		"args",
+		NewTerminal("(", "("),
+		NewSepBy1("items", number, NewTerminal(",", ",")),
+		NewTerminal(")", ")"),
+	)
+
+	comment := NewBound(
+		"comment",
+		NewTerminal("/*", "/*"),
+		nil,
+		NewTerminal("*/", "*/"),
+	)
+	nestedComment := NewBoundNested(
+		"comment",
+		NewTerminal("/*", "/*"),
+		NewTerminal("*/", "*/"),
+	)
+
+	code := NewChain(
+		"code",
+		NewRegexp("text", "^[a-z\n ]*"),
+		comment,
+	)
+
+	samples := []struct {
+		text string
+		rule Rule
+		data []string
+		err  error
+	}{
+		{"(1)", parens, []string{"(", "1", ")"}, nil},
+		{"(1+(2+3))", parens, []string{"(", "1+(2+3)", ")"}, nil},
+		{
+			"(1+2",
+			parens,
+			nil,
+			NewErrBoundIncomplete(
+				[]byte("("),
+				[]byte(")"),
+				&Location{Path: DefaultParserPath},
+			),
+		},
+		{
+			"(1+(2+",
+			parens,
+			nil,
+			NewErrUnexpectedToken(
+				NewTerminal(")", ")"),
+				&Location{
+					Path:     DefaultParserPath,
+					Position: 2,
+					Column:   2,
+				},
+				[]byte("+(2+"),
+			),
+		},
+		{
+			"(1 2)",
+			parens,
+			nil,
+			NewErrUnexpectedToken(
+				NewTerminal(")", ")"),
+				&Location{
+					Path:     DefaultParserPath,
+					Position: 2,
+					Column:   2,
+				},
+				[]byte(" 2)"),
+			),
+		},
+		{"(1,2)", tuple, []string{"(1,2)"}, nil},
+		{
+			"(1+",
+			addition,
+			nil,
+			NewErrBoundIncomplete(
+				[]byte("("),
+				[]byte(")"),
+				&Location{Path: DefaultParserPath},
+			),
+		},
+		{
+			"(1,",
+			args,
+			nil,
+			NewErrBoundIncomplete(
+				[]byte("("),
+				[]byte(")"),
+				&Location{Path: DefaultParserPath},
+			),
+		},
+		{
+			"(1+x)",
+			addition,
+			nil,
+			NewErrUnexpectedToken(
+				number,
+				&Location{
+					Path:     DefaultParserPath,
+					Position: 3,
+					Column:   3,
+				},
+				[]byte("x)"),
+			),
+		},
+		{
+			"1+2",
+			parens,
+			nil,
+			NewErrUnexpectedToken(
+				NewTerminal("(", "("),
+				&Location{Path: DefaultParserPath},
+				[]byte("1+2"),
+			),
+		},
+		{"/* foo */", comment, []string{"/*", "*/"}, nil},
+		{"x\n  /* foo */", code, []string{"x\n  ", "/* foo */"}, nil},
+		{
+			"x\n  /* foo",
+			code,
+			nil,
+			NewErrBoundIncomplete(
+				[]byte("/*"),
+				[]byte("*/"),
+				&Location{
+					Path:     DefaultParserPath,
+					Position: 4,
+					Line:     1,
+					Column:   2,
+				},
+			),
+		},
+		{"/* /* foo */", comment, []string{"/*", "*/"}, nil},
+		{"/* /* foo */ */", nestedComment, []string{"/*", "*/"}, nil},
+		{
+			"/* /* foo */",
+			nestedComment,
+			nil,
+			NewErrBoundIncomplete(
+				[]byte("/*"),
+				[]byte("*/"),
+				&Location{Path: DefaultParserPath},
+			),
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample.text)
+			tree, err := Parse(sample.rule, []byte(sample.text))
+			assert.EqualValues(t, sample.err, err, msg)
+			if sample.err != nil {
+				return
+			}
+
+			data := make([]string, len(tree.Childs))
+			for n, child := range tree.Childs {
+				data[n] = string(child.Data)
+			}
+			assert.Equal(t, sample.data, data, msg)
+			assert.Equal(t, []byte(sample.text), tree.Data, msg)
+		})
+	}
+}
@@ -7,10 +7,18 @@ import (
 var _ Rule = new(Chain)
 
 // Chain represents a chain of Rule's to match in the data.
+//
+// Sync rules are used when Parser.Recover is enabled: if some
+// Rule fails after the Chain consumed part of the input then
+// input is skipped up to the end of the first Sync match and
+// an error Tree (see Tree.Err) is added to the Chain childs
+// in place of the failed Rule and the rest of the Chain.
 type Chain struct {
-	name  string
-	Rules Rules
-	Hooks []RuleParseHook
+	name       string
+	Rules      Rules
+	Sync       Rules
+	Hooks      []RuleParseHook
+	EnterHooks []RuleEnterHook
 }
 
 // Name indicates the name which was given to the rule
@@ -68,6 +76,10 @@ func (r *Chain) IsFinite() bool {
 // May return an error if something goes wrong, should provide some
 // location information to the user which points to position in input.
 func (r *Chain) Parse(ctx *Context, input []byte) (*Tree, error) {
+	if err := runEnterHooks(ctx, r, input, r.EnterHooks); err != nil {
+		return nil, err
+	}
+
 	if len(r.Rules) == 0 {
 		return nil, NewErrEmptyRule(r, ctx.Rule)
 	}
@@ -88,7 +100,8 @@ func (r *Chain) Parse(ctx *Context, input []byte) (*Tree, error) {
 	)
 	for _, sr := range r.Rules {
 		line, col = ctx.Parser.Locate(pos)
-		subTrees[n], err = sr.Parse(
+		subTrees[n], err = ctx.Parser.ParseRule(
+			sr,
 			&Context{
 				Rule:   r,
 				Parser: ctx.Parser,
@@ -99,13 +112,22 @@ func (r *Chain) Parse(ctx *Context, input []byte) (*Tree, error) {
 					Column:   col,
 				},
 				Depth: nextDepth,
+				State: ctx.State,
 			},
 			subInput,
 		)
 		if err != nil {
 			if err == ErrSkipRule {
+				err = nil // NOTE: skipped rule should not skip the whole chain
 				continue
 			}
+			if errPos, ok := errorPosition(err); ok && errPos > ctx.Location.Position {
+				subTrees[n] = recoverSync(ctx, r, r.Sync, sr, pos, subInput, err)
+				if subTrees[n] != nil {
+					n++
+					break
+				}
+			}
 			return nil, err
 		}
 		movPos = subTrees[n].Region.End - subTrees[n].Region.Start
@@ -113,8 +135,8 @@ func (r *Chain) Parse(ctx *Context, input []byte) (*Tree, error) {
 		subInput = subInput[movPos:]
 		n++
 	}
-	if err != nil {
-		return nil, err
+	if n == 0 { // NOTE: every Rule was skipped
+		return nil, ErrSkipRule
 	}
 	subTrees = subTrees[:n] // NOTE: because some Rule's could be skipped
 
@@ -133,8 +155,8 @@ func (r *Chain) Parse(ctx *Context, input []byte) (*Tree, error) {
 		Childs: subTrees,
 		Data:   input[:region.End-region.Start],
 	}
-	for _, hook := range r.Hooks {
-		hook(ctx, tree)
+	if err := runHooks(ctx, r, tree, r.Hooks); err != nil {
+		return nil, err
 	}
 	return tree, nil
 }
@@ -146,13 +168,22 @@ func (r *Chain) Add(rule ...Rule) {
 	r.Rules = append(r.Rules, rule...)
 }
 
+// WithSync sets rules which are used to recover
+// from errors and returns the Chain.
+func (r *Chain) WithSync(rules ...Rule) *Chain {
+	r.Sync = rules
+	return r
+}
+
 //
 
 // NewChain constructs new Chain.
 // Valid Chain could be constructed with >=2 rules.
+// Accepts RuleParseHook and RuleEnterHook in addition to rules.
 func NewChain(name string, rulesOrHooks ...interface{}) *Chain {
 	rules := []Rule{}
 	hooks := []RuleParseHook{}
+	var enterHooks []RuleEnterHook
 	for _, ruleOrHook := range rulesOrHooks {
 		switch v := ruleOrHook.(type) {
 		case Rule:
@@ -161,13 +192,16 @@ func NewChain(name string, rulesOrHooks ...interface{}) *Chain {
 			rules = append(rules, nil)
 		case RuleParseHook:
 			hooks = append(hooks, v)
+		case RuleEnterHook:
+			enterHooks = append(enterHooks, v)
 		default:
 			panic(fmt.Sprintf("unsupported type %T", ruleOrHook))
 		}
 	}
 	return &Chain{
-		name:  name,
-		Rules: rules,
-		Hooks: hooks,
+		name:       name,
+		Rules:      rules,
+		Hooks:      hooks,
+		EnterHooks: enterHooks,
 	}
 }
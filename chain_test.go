@@ -426,6 +426,68 @@ func TestChain(t *testing.T) {
 			nil,
 			DefaultParser,
 		},
+		{
+			"foo",
+			NewChain(
+				"foo maybe bar",
+				NewTerminal("foo", "foo"),
+				NewRepetitionTimesVariadic(
+					"maybe bar",
+					0,
+					NewTerminal("bar", "bar"),
+				),
+			),
+			&Tree{
+				Rule: NewChain(
+					"foo maybe bar",
+					NewTerminal("foo", "foo"),
+					NewRepetitionTimesVariadic(
+						"maybe bar",
+						0,
+						NewTerminal("bar", "bar"),
+					),
+				),
+				Location: &Location{Path: DefaultParserPath},
+				Region: &Region{
+					Start: 0,
+					End:   3,
+				},
+				Childs: []*Tree{
+					{
+						Rule:     NewTerminal("foo", "foo"),
+						Location: &Location{Path: DefaultParserPath},
+						Region: &Region{
+							Start: 0,
+							End:   3,
+						},
+						Depth: 1,
+						Data:  []byte("foo"),
+					},
+					{
+						Rule: NewRepetitionTimesVariadic(
+							"maybe bar",
+							0,
+							NewTerminal("bar", "bar"),
+						),
+						Location: &Location{
+							Path:     DefaultParserPath,
+							Position: 3,
+							Column:   2,
+						},
+						Region: &Region{
+							Start: 3,
+							End:   3,
+						},
+						Depth:  1,
+						Childs: []*Tree{},
+						Data:   []byte{},
+					},
+				},
+				Data: []byte("foo"),
+			},
+			nil,
+			DefaultParser,
+		},
 	}
 
 	for k, sample := range samples {
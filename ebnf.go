@@ -0,0 +1,728 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LoadEBNF reads a textual EBNF grammar and constructs
+// a Rule graph for each production, keyed by production name.
+//
+// Supported syntax is a mix of ISO and W3C EBNF flavors:
+//
+//	name = expression ;           production, `::=` and trailing `.` are also accepted,
+//	                              terminator could be omitted
+//	a , b  or  a b                concatenation (*Chain)
+//	a | b                         alternation (*Either)
+//	[ a ]  or  a?                 optional (*Optional)
+//	{ a }  or  a*                 zero or more (*Repetition)
+//	a+                            one or more (*Repetition)
+//	3 * a                         exactly 3 times (*Repetition)
+//	( a )                         grouping
+//	"literal"  or  'literal'      *Terminal
+//	/regexp/                      *Regexp, anchored to the current position
+//	? case-insensitive "x" ?      *Terminal which matches using case-folding
+//	? text ?                      unresolved *Ref named text, a placeholder
+//	                              for the rule which has no EBNF equivalent
+//	(* comment *)
+//
+// References could point to productions defined later in the text
+// and could be recursive.
+// Syntax errors and undefined references are reported
+// as *ErrGrammar with the Location inside the grammar text.
+func LoadEBNF(r io.Reader) (map[string]Rule, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &ebnfParser{lexer: &ebnfLexer{input: buf}}
+	productions, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return ebnfBuild(productions)
+}
+
+//
+
+type ebnfTokenKind int
+
+const (
+	ebnfTokenEOF ebnfTokenKind = iota
+	ebnfTokenIdentifier
+	ebnfTokenLiteral
+	ebnfTokenRegexp
+	ebnfTokenInteger
+	ebnfTokenPunct
+)
+
+type ebnfToken struct {
+	kind     ebnfTokenKind
+	value    string
+	location *Location
+}
+
+func (t *ebnfToken) String() string {
+	switch t.kind {
+	case ebnfTokenEOF:
+		return "EOF"
+	case ebnfTokenLiteral:
+		return strconv.Quote(t.value)
+	case ebnfTokenRegexp:
+		return "/" + t.value + "/"
+	default:
+		return "'" + t.value + "'"
+	}
+}
+
+func (t *ebnfToken) is(punct string) bool {
+	return t.kind == ebnfTokenPunct && t.value == punct
+}
+
+// ebnfLexer splits EBNF grammar text into tokens.
+type ebnfLexer struct {
+	input        []byte
+	position     int
+	line, column int
+}
+
+func (l *ebnfLexer) location() *Location {
+	return &Location{
+		Path:     DefaultParserPath,
+		Position: l.position,
+		Line:     l.line,
+		Column:   l.column,
+	}
+}
+
+func (l *ebnfLexer) peek(n int) byte {
+	if l.position+n >= len(l.input) {
+		return 0
+	}
+	return l.input[l.position+n]
+}
+
+func (l *ebnfLexer) advance() rune {
+	r, size := utf8.DecodeRune(l.input[l.position:])
+	l.position += size
+	if r == '\n' {
+		l.line++
+		l.column = 0
+	} else {
+		l.column++
+	}
+	return r
+}
+
+func (l *ebnfLexer) skip() error {
+	for l.position < len(l.input) {
+		switch {
+		case l.peek(0) == '(' && l.peek(1) == '*':
+			loc := l.location()
+			l.advance()
+			l.advance()
+			for !(l.peek(0) == '*' && l.peek(1) == ')') {
+				if l.position >= len(l.input) {
+					return NewErrGrammar(loc, errors.New("unterminated comment"))
+				}
+				l.advance()
+			}
+			l.advance()
+			l.advance()
+		case unicode.IsSpace(rune(l.peek(0))):
+			l.advance()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (l *ebnfLexer) next() (*ebnfToken, error) {
+	err := l.skip()
+	if err != nil {
+		return nil, err
+	}
+
+	loc := l.location()
+	if l.position >= len(l.input) {
+		return &ebnfToken{kind: ebnfTokenEOF, location: loc}, nil
+	}
+
+	c := l.peek(0)
+	switch {
+	case c == ':' && l.peek(1) == ':' && l.peek(2) == '=':
+		l.advance()
+		l.advance()
+		l.advance()
+		return &ebnfToken{ebnfTokenPunct, "::=", loc}, nil
+	case strings.IndexByte("=;.|,()[]{}?*+", c) >= 0:
+		l.advance()
+		return &ebnfToken{ebnfTokenPunct, string(c), loc}, nil
+	case c == '"' || c == '\'':
+		return l.literal(loc)
+	case c == '/':
+		return l.regexp(loc)
+	case c >= '0' && c <= '9':
+		start := l.position
+		for l.peek(0) >= '0' && l.peek(0) <= '9' {
+			l.advance()
+		}
+		return &ebnfToken{ebnfTokenInteger, string(l.input[start:l.position]), loc}, nil
+	case c == '_' || c >= utf8.RuneSelf || unicode.IsLetter(rune(c)):
+		start := l.position
+		for l.position < len(l.input) {
+			r, _ := utf8.DecodeRune(l.input[l.position:])
+			if r != '_' && r != '-' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				break
+			}
+			l.advance()
+		}
+		if start == l.position {
+			break
+		}
+		return &ebnfToken{ebnfTokenIdentifier, string(l.input[start:l.position]), loc}, nil
+	}
+
+	return nil, NewErrGrammar(loc, fmt.Errorf("unexpected character %q", l.advance()))
+}
+
+func (l *ebnfLexer) literal(loc *Location) (*ebnfToken, error) {
+	var (
+		quote = l.advance()
+		value = []rune{}
+	)
+	for {
+		if l.position >= len(l.input) {
+			return nil, NewErrGrammar(loc, errors.New("unterminated literal"))
+		}
+		r := l.advance()
+		switch r {
+		case quote:
+			if len(value) == 0 {
+				return nil, NewErrGrammar(loc, errors.New("empty literal"))
+			}
+			return &ebnfToken{ebnfTokenLiteral, string(value), loc}, nil
+		case '\\':
+			if l.position >= len(l.input) {
+				continue
+			}
+			switch e := l.advance(); e {
+			case 'n':
+				value = append(value, '\n')
+			case 'r':
+				value = append(value, '\r')
+			case 't':
+				value = append(value, '\t')
+			default:
+				value = append(value, e)
+			}
+		default:
+			value = append(value, r)
+		}
+	}
+}
+
+// special reads the special sequence text up to the closing `?`,
+// opening `?` is expected to be consumed already.
+func (l *ebnfLexer) special(loc *Location) (string, error) {
+	start := l.position
+	for {
+		if l.position >= len(l.input) {
+			return "", NewErrGrammar(loc, errors.New("unterminated special sequence"))
+		}
+		if l.peek(0) == '?' {
+			text := strings.TrimSpace(string(l.input[start:l.position]))
+			l.advance()
+			return text, nil
+		}
+		l.advance()
+	}
+}
+
+func (l *ebnfLexer) regexp(loc *Location) (*ebnfToken, error) {
+	l.advance()
+	value := []rune{}
+	for {
+		if l.position >= len(l.input) || l.peek(0) == '\n' {
+			return nil, NewErrGrammar(loc, errors.New("unterminated regexp"))
+		}
+		r := l.advance()
+		switch {
+		case r == '/':
+			return &ebnfToken{ebnfTokenRegexp, string(value), loc}, nil
+		case r == '\\' && l.peek(0) == '/':
+			value = append(value, l.advance())
+		default:
+			value = append(value, r)
+		}
+	}
+}
+
+//
+
+type ebnfNodeKind int
+
+const (
+	ebnfNodeReference ebnfNodeKind = iota
+	ebnfNodeTerminal
+	ebnfNodeRegexp
+	ebnfNodeChain
+	ebnfNodeEither
+	ebnfNodeOptional
+	ebnfNodeRepetition
+	ebnfNodeSpecial
+)
+
+// ebnfNode is a node of the EBNF expression syntax tree.
+type ebnfNode struct {
+	kind     ebnfNodeKind
+	value    string
+	times    int
+	variadic bool
+	childs   []*ebnfNode
+	location *Location
+}
+
+type ebnfProduction struct {
+	name     string
+	node     *ebnfNode
+	location *Location
+}
+
+// ebnfParser is a recursive descent parser for EBNF grammar text.
+type ebnfParser struct {
+	lexer   *ebnfLexer
+	token   *ebnfToken
+	pending *ebnfToken
+}
+
+func (p *ebnfParser) next() error {
+	var err error
+	if p.pending != nil {
+		p.token, p.pending = p.pending, nil
+		return nil
+	}
+	p.token, err = p.lexer.next()
+	return err
+}
+
+// lookahead returns a token which follows the current token.
+func (p *ebnfParser) lookahead() (*ebnfToken, error) {
+	var err error
+	if p.pending == nil {
+		p.pending, err = p.lexer.next()
+	}
+	return p.pending, err
+}
+
+func (p *ebnfParser) unexpected(want string) error {
+	return NewErrGrammar(
+		p.token.location,
+		fmt.Errorf("unexpected %s, want %s", p.token, want),
+	)
+}
+
+func (p *ebnfParser) expect(punct string) error {
+	if !p.token.is(punct) {
+		return p.unexpected("'" + punct + "'")
+	}
+	return p.next()
+}
+
+func (p *ebnfParser) parse() ([]*ebnfProduction, error) {
+	err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	productions := []*ebnfProduction{}
+	for p.token.kind != ebnfTokenEOF {
+		production, err := p.production()
+		if err != nil {
+			return nil, err
+		}
+		productions = append(productions, production)
+	}
+	return productions, nil
+}
+
+func (p *ebnfParser) production() (*ebnfProduction, error) {
+	if p.token.kind != ebnfTokenIdentifier {
+		return nil, p.unexpected("production name")
+	}
+	production := &ebnfProduction{
+		name:     p.token.value,
+		location: p.token.location,
+	}
+
+	err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if !p.token.is("=") && !p.token.is("::=") {
+		return nil, p.unexpected("'=' or '::='")
+	}
+	err = p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	production.node, err = p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.token.is(";"), p.token.is("."):
+		err = p.next()
+		if err != nil {
+			return nil, err
+		}
+	case p.token.kind == ebnfTokenEOF, p.token.kind == ebnfTokenIdentifier:
+	default:
+		return nil, p.unexpected("';'")
+	}
+	return production, nil
+}
+
+func (p *ebnfParser) expression() (*ebnfNode, error) {
+	node, err := p.sequence()
+	if err != nil {
+		return nil, err
+	}
+	if !p.token.is("|") {
+		return node, nil
+	}
+
+	either := &ebnfNode{
+		kind:     ebnfNodeEither,
+		childs:   []*ebnfNode{node},
+		location: node.location,
+	}
+	for p.token.is("|") {
+		err = p.next()
+		if err != nil {
+			return nil, err
+		}
+		node, err = p.sequence()
+		if err != nil {
+			return nil, err
+		}
+		either.childs = append(either.childs, node)
+	}
+	return either, nil
+}
+
+// sequenceEnds reports whether current token could not start a next term.
+func (p *ebnfParser) sequenceEnds() (bool, error) {
+	switch p.token.kind {
+	case ebnfTokenEOF:
+		return true, nil
+	case ebnfTokenIdentifier:
+		// NOTE: production terminator could be omitted,
+		// so `name =` starts a next production
+		next, err := p.lookahead()
+		if err != nil {
+			return false, err
+		}
+		return next.is("=") || next.is("::="), nil
+	case ebnfTokenPunct:
+		switch p.token.value {
+		case "(", "[", "{":
+			return false, nil
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func (p *ebnfParser) sequence() (*ebnfNode, error) {
+	node, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	chain := &ebnfNode{
+		kind:     ebnfNodeChain,
+		childs:   []*ebnfNode{node},
+		location: node.location,
+	}
+	for {
+		if p.token.is(",") {
+			err = p.next()
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			ends, err := p.sequenceEnds()
+			if err != nil {
+				return nil, err
+			}
+			if ends {
+				break
+			}
+		}
+		node, err = p.term()
+		if err != nil {
+			return nil, err
+		}
+		chain.childs = append(chain.childs, node)
+	}
+	if len(chain.childs) == 1 {
+		return chain.childs[0], nil
+	}
+	return chain, nil
+}
+
+func (p *ebnfParser) term() (*ebnfNode, error) {
+	var (
+		loc   = p.token.location
+		times = -1
+		err   error
+	)
+	if p.token.kind == ebnfTokenInteger {
+		times, err = strconv.Atoi(p.token.value)
+		if err != nil {
+			return nil, NewErrGrammar(loc, err)
+		}
+		err = p.next()
+		if err != nil {
+			return nil, err
+		}
+		err = p.expect("*")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	node, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+
+	if times >= 0 {
+		return &ebnfNode{
+			kind:     ebnfNodeRepetition,
+			times:    times,
+			childs:   []*ebnfNode{node},
+			location: loc,
+		}, nil
+	}
+
+	switch {
+	case p.token.is("?"):
+		node = &ebnfNode{kind: ebnfNodeOptional, childs: []*ebnfNode{node}, location: loc}
+	case p.token.is("*"):
+		node = &ebnfNode{kind: ebnfNodeRepetition, variadic: true, childs: []*ebnfNode{node}, location: loc}
+	case p.token.is("+"):
+		node = &ebnfNode{kind: ebnfNodeRepetition, times: 1, variadic: true, childs: []*ebnfNode{node}, location: loc}
+	default:
+		return node, nil
+	}
+	return node, p.next()
+}
+
+func (p *ebnfParser) factor() (*ebnfNode, error) {
+	var (
+		token = p.token
+		node  *ebnfNode
+		err   error
+	)
+	switch {
+	case token.kind == ebnfTokenIdentifier:
+		node = &ebnfNode{kind: ebnfNodeReference, value: token.value, location: token.location}
+	case token.kind == ebnfTokenLiteral:
+		node = &ebnfNode{kind: ebnfNodeTerminal, value: token.value, location: token.location}
+	case token.kind == ebnfTokenRegexp:
+		node = &ebnfNode{kind: ebnfNodeRegexp, value: token.value, location: token.location}
+	case token.is("?") && p.pending == nil:
+		// NOTE: `?` in place of the factor opens a special sequence,
+		// postfix `?` (optional) is handled by term
+		text, err := p.lexer.special(token.location)
+		if err != nil {
+			return nil, err
+		}
+		node = &ebnfNode{kind: ebnfNodeSpecial, value: text, location: token.location}
+	case token.is("("), token.is("["), token.is("{"):
+		err = p.next()
+		if err != nil {
+			return nil, err
+		}
+		node, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+		switch token.value {
+		case "(":
+			return node, p.expect(")")
+		case "[":
+			node = &ebnfNode{kind: ebnfNodeOptional, childs: []*ebnfNode{node}, location: token.location}
+			return node, p.expect("]")
+		default:
+			node = &ebnfNode{kind: ebnfNodeRepetition, variadic: true, childs: []*ebnfNode{node}, location: token.location}
+			return node, p.expect("}")
+		}
+	default:
+		return nil, p.unexpected("expression")
+	}
+	return node, p.next()
+}
+
+//
+
+// ebnfBuilder constructs Rule graph from EBNF productions.
+type ebnfBuilder struct {
+	rules map[string]Rule
+	errs  []error
+}
+
+func ebnfBuild(productions []*ebnfProduction) (map[string]Rule, error) {
+	b := &ebnfBuilder{rules: make(map[string]Rule, len(productions))}
+
+	// NOTE: productions are created empty first,
+	// so references could be resolved in any order
+	// and could be recursive.
+	// Only productions which created a shell are filled,
+	// duplicates are reported and skipped.
+	defined := make([]*ebnfProduction, 0, len(productions))
+	for _, production := range productions {
+		if _, ok := b.rules[production.name]; ok {
+			b.errs = append(b.errs, NewErrGrammar(
+				production.location,
+				fmt.Errorf("duplicate production %q", production.name),
+			))
+			continue
+		}
+		b.rules[production.name] = b.shell(production.name, production.node)
+		defined = append(defined, production)
+	}
+	for _, production := range defined {
+		b.fill(production.name, b.rules[production.name], production.node)
+	}
+
+	if len(b.errs) > 0 {
+		return nil, errors.Join(b.errs...)
+	}
+	return b.rules, nil
+}
+
+// shell creates an empty Rule for the production which will be filled later.
+func (b *ebnfBuilder) shell(name string, node *ebnfNode) Rule {
+	switch node.kind {
+	case ebnfNodeChain:
+		return NewChain(name)
+	case ebnfNodeEither:
+		return NewEither(name)
+	case ebnfNodeOptional:
+		return NewOptional(name, nil)
+	case ebnfNodeRepetition:
+		if node.variadic {
+			return NewRepetitionTimesVariadic(name, node.times, nil)
+		}
+		return NewRepetitionTimes(name, node.times, nil)
+	case ebnfNodeTerminal:
+		return NewTerminal(name, node.value)
+	case ebnfNodeRegexp:
+		return b.regexp(name, node)
+	case ebnfNodeSpecial:
+		if value, ok := ebnfCaseInsensitive(node.value); ok {
+			return NewTerminalFold(name, value)
+		}
+		return NewWrapper(name, nil)
+	default:
+		return NewWrapper(name, nil)
+	}
+}
+
+// fill populates the production Rule created by shell.
+func (b *ebnfBuilder) fill(name string, rule Rule, node *ebnfNode) {
+	switch r := rule.(type) {
+	case *Chain:
+		r.Add(b.list(name, node.childs)...)
+	case *Either:
+		r.Add(b.list(name, node.childs)...)
+	case *Repetition:
+		r.Rule = b.rule(name, node.childs[0])
+	case *Optional:
+		r.Rule = b.rule(name, node.childs[0])
+	case *Wrapper:
+		r.Rule = b.rule(name, node)
+	}
+}
+
+func (b *ebnfBuilder) list(name string, nodes []*ebnfNode) []Rule {
+	rules := make([]Rule, len(nodes))
+	for k, node := range nodes {
+		rules[k] = b.rule(name, node)
+	}
+	return rules
+}
+
+// rule constructs a Rule for the node of the production with specified name.
+func (b *ebnfBuilder) rule(name string, node *ebnfNode) Rule {
+	switch node.kind {
+	case ebnfNodeReference:
+		rule, ok := b.rules[node.value]
+		if !ok {
+			b.errs = append(b.errs, NewErrGrammar(
+				node.location,
+				fmt.Errorf("undefined production %q", node.value),
+			))
+		}
+		return rule
+	case ebnfNodeTerminal:
+		return NewTerminal(node.value, node.value)
+	case ebnfNodeRegexp:
+		return b.regexp(name, node)
+	case ebnfNodeSpecial:
+		if value, ok := ebnfCaseInsensitive(node.value); ok {
+			return NewTerminalFold(value, value)
+		}
+		return NewRef(node.value)
+	default:
+		rule := b.shell(name, node)
+		b.fill(name, rule, node)
+		return rule
+	}
+}
+
+// regexp constructs a Regexp anchored to the current position.
+func (b *ebnfBuilder) regexp(name string, node *ebnfNode) Rule {
+	expr := node.value
+	if !strings.HasPrefix(expr, "^") {
+		expr = "^(?:" + expr + ")"
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		b.errs = append(b.errs, NewErrGrammar(node.location, err))
+		return nil
+	}
+	return &Regexp{
+		name:   name,
+		Regexp: re,
+		Expr:   expr,
+	}
+}
+
+// ebnfCaseInsensitive returns a literal of the special sequence
+// `case-insensitive "literal"` which is emitted by FormatEBNF
+// for case-folding terminals.
+func ebnfCaseInsensitive(text string) (string, bool) {
+	quoted, ok := strings.CutPrefix(text, "case-insensitive ")
+	if !ok {
+		return "", false
+	}
+	value, err := strconv.Unquote(strings.TrimSpace(quoted))
+	if err != nil || value == "" {
+		return "", false
+	}
+	return value, true
+}
@@ -0,0 +1,139 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadEBNF(t *testing.T) {
+	grammar := `
+(* arithmetic expressions *)
+expression = term , { ( "+" | "-" ) , term } ;
+term       = factor { ("*" | "/") factor } ;
+factor     = number | "(" expression ")" ;
+number     ::= /[0-9]+/ .
+list       = "[" [ number { "," number } ] "]"
+pair       = 2 * digit
+digits     = digit+
+digit      = /[0-9]/
+`
+	rules, err := LoadEBNF(strings.NewReader(grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.IsType(t, &Chain{}, rules["expression"])
+	assert.IsType(t, &Chain{}, rules["term"])
+	assert.IsType(t, &Either{}, rules["factor"])
+	assert.IsType(t, &Regexp{}, rules["number"])
+	assert.IsType(t, &Chain{}, rules["list"])
+	assert.IsType(t, &Optional{}, rules["list"].(*Chain).Rules[1])
+	assert.IsType(t, &Repetition{}, rules["pair"])
+	assert.IsType(t, &Repetition{}, rules["digits"])
+	assert.Equal(t, "factor", rules["factor"].Name())
+	assert.Equal(t, "^(?:[0-9]+)", rules["number"].(*Regexp).Expr)
+	assert.Equal(t, 2, rules["pair"].(*Repetition).Times)
+	assert.False(t, rules["pair"].(*Repetition).Variadic)
+
+	samples := []struct {
+		production string
+		text       string
+		err        bool
+	}{
+		{"expression", "1", false},
+		{"expression", "1+2*(3-4)/5", false},
+		{"expression", "1+", true},
+		{"expression", "(1", true},
+		{"list", "[]", false},
+		{"list", "[1,2,3]", false},
+		{"list", "[1,]", true},
+		{"pair", "12", false},
+		{"pair", "1", true},
+		{"digits", "123", false},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample)
+			tree, err := Parse(rules[sample.production], []byte(sample.text))
+			if sample.err {
+				assert.NotNil(t, err, msg)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, []byte(sample.text), tree.Data, msg)
+		})
+	}
+}
+
+func TestLoadEBNFErrors(t *testing.T) {
+	samples := []struct {
+		grammar string
+		err     []*Location
+	}{
+		{
+			"foo = \"foo\" ;\nbar = foo baz ;",
+			[]*Location{{DefaultParserPath, 24, 1, 10}},
+		},
+		{
+			"foo = \"foo\" ;\nfoo = \"bar\" ;",
+			[]*Location{{DefaultParserPath, 14, 1, 0}},
+		},
+		{
+			"a = {\"x\"} ;\na = \"y\" ;",
+			[]*Location{{DefaultParserPath, 12, 1, 0}},
+		},
+		{
+			"foo = ( \"foo\" ;",
+			[]*Location{{DefaultParserPath, 14, 0, 14}},
+		},
+		{
+			"foo = \"foo\" ;\n  = \"bar\" ;",
+			[]*Location{{DefaultParserPath, 16, 1, 2}},
+		},
+		{
+			"foo = \"foo ;",
+			[]*Location{{DefaultParserPath, 6, 0, 6}},
+		},
+		{
+			"foo = /[/ ;",
+			[]*Location{{DefaultParserPath, 6, 0, 6}},
+		},
+		{
+			"foo = bar | baz ;",
+			[]*Location{
+				{DefaultParserPath, 6, 0, 6},
+				{DefaultParserPath, 12, 0, 12},
+			},
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample)
+			_, err := LoadEBNF(strings.NewReader(sample.grammar))
+			if !assert.NotNil(t, err, msg) {
+				return
+			}
+
+			errs := []error{err}
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				errs = joined.Unwrap()
+			}
+			locs := make([]*Location, len(errs))
+			for n, err := range errs {
+				var grammarErr *ErrGrammar
+				if !errors.As(err, &grammarErr) {
+					t.Fatalf("unexpected error type %T: %s", err, err)
+				}
+				locs[n] = grammarErr.Location
+			}
+			assert.Equal(t, sample.err, locs, msg)
+		})
+	}
+}
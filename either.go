@@ -8,12 +8,28 @@ var _ Rule = new(Either)
 
 // Either represents a list of Rule's to match in the data.
 // One of the rules in a list must match.
+//
+// By default it is an ordered choice: first matched Rule wins.
+// If Longest is set then every Rule is tried and the one which
+// consumes most of the input wins, if several rules consume the
+// same amount of input then first of them wins and AmbiguityHooks
+// are called with all matched trees.
+// Tree.Value of the matched Rule is passed to the Either Tree.
 type Either struct {
-	name  string
-	Rules Rules
-	Hooks []RuleParseHook
+	name           string
+	Rules          Rules
+	Longest        bool
+	Hooks          []RuleParseHook
+	EnterHooks     []RuleEnterHook
+	AmbiguityHooks []RuleAmbiguityHook
 }
 
+// RuleAmbiguityHook is called by the Rule when input
+// could be matched in several ways, trees contains
+// every match in order of preference.
+// Returned error aborts the parsing.
+type RuleAmbiguityHook = func(ctx *Context, trees []*Tree) error
+
 // Name indicates the name which was given to the rule
 // on creation. Name could be not unique.
 func (r *Either) Name() string {
@@ -53,9 +69,13 @@ func (r *Either) GetChilds() Treers {
 
 // GetParameters returns a KV rule parameters.
 func (r *Either) GetParameters() RuleParameters {
-	return RuleParameters{
+	params := RuleParameters{
 		"name": r.name,
 	}
+	if r.Longest {
+		params["longest"] = r.Longest
+	}
+	return params
 }
 
 // IsFinite returns true if this rule is
@@ -69,6 +89,10 @@ func (r *Either) IsFinite() bool {
 // May return an error if something goes wrong, should provide some
 // location information to the user which points to position in input.
 func (r *Either) Parse(ctx *Context, input []byte) (*Tree, error) {
+	if err := runEnterHooks(ctx, r, input, r.EnterHooks); err != nil {
+		return nil, err
+	}
+
 	if len(r.Rules) == 0 {
 		return nil, NewErrEmptyRule(r, ctx.Rule)
 	}
@@ -85,12 +109,18 @@ func (r *Either) Parse(ctx *Context, input []byte) (*Tree, error) {
 	}
 
 	var (
-		subTree   *Tree
-		line, col = ctx.Parser.Locate(ctx.Location.Position)
-		err       error
+		subTree     *Tree
+		longest     []*Tree
+		longestRule Rule
+		line, col   = ctx.Parser.Locate(ctx.Location.Position)
+		outer       = ctx.Parser.failure
+		snapshot    = ctx.snapshot()
+		suppressed  bool // match was found with error recovery suppressed
+		err         error
 	)
-	for _, sr := range r.Rules {
-		subTree, err = sr.Parse(
+	parse := func(sr Rule) (*Tree, error) {
+		return ctx.Parser.ParseRule(
+			sr,
 			&Context{
 				Rule:   sr,
 				Parser: ctx.Parser,
@@ -101,23 +131,105 @@ func (r *Either) Parse(ctx *Context, input []byte) (*Tree, error) {
 					Column:   col,
 				},
 				Depth: nextDepth,
+				State: ctx.State,
 			},
 			input,
 		)
-		if err != nil {
-			if err == ErrSkipRule {
+	}
+	alternatives := func() error {
+		for _, sr := range r.Rules {
+			if !ctx.Parser.predict(sr, input) {
+				for _, expected := range ctx.Parser.analysis.expected(sr) {
+					ctx.Parser.failure.add(expected, ctx.Location)
+				}
+				err = NewErrUnexpectedToken(sr, ctx.Location, ShowInput(input))
 				continue
 			}
-			switch err.(type) {
-			case *ErrUnexpectedToken, *ErrUnexpectedEOF:
-				continue
-			default:
+			subTree, err = parse(sr)
+			if err != nil {
+				snapshot.restore()
+				if err == ErrSkipRule {
+					continue
+				}
+				switch err.(type) {
+				case *ErrUnexpectedToken, *ErrUnexpectedEOF:
+					continue
+				default:
+					return err
+				}
+			}
+			if !r.Longest {
+				return nil
+			}
+			snapshot.restore() // NOTE: winner is applied again when all rules are tried
+			switch {
+			case len(longest) == 0 || subTree.Region.End > longest[0].Region.End:
+				longest = []*Tree{subTree}
+				longestRule = sr
+			case subTree.Region.End == longest[0].Region.End:
+				longest = append(longest, subTree)
+			}
+		}
+		return nil
+	}
+	ctx.Parser.failure = parserFailure{}
+	if ctx.Parser.recovering() {
+		// NOTE: error recovery is a last resort, alternatives
+		// are tried without it first, so input which is valid
+		// without recovery yields the same tree
+		ctx.Parser.noRecover++
+		fatal := alternatives()
+		ctx.Parser.noRecover--
+		if fatal != nil {
+			return nil, fatal
+		}
+		suppressed = subTree != nil || len(longest) > 0
+		if !suppressed {
+			ctx.Parser.failure = parserFailure{}
+			fatal = alternatives()
+			if fatal != nil {
+				return nil, fatal
+			}
+		}
+	} else if fatal := alternatives(); fatal != nil {
+		return nil, fatal
+	}
+	failure := ctx.Parser.failure
+	ctx.Parser.failure = outer
+	ctx.Parser.failure.merge(failure)
+
+	if len(longest) > 0 {
+		subTree, err = longest[0], nil
+		if snapshot != nil {
+			if suppressed {
+				ctx.Parser.noRecover++
+			}
+			subTree, err = parse(longestRule)
+			if suppressed {
+				ctx.Parser.noRecover--
+			}
+			if err != nil {
 				return nil, err
 			}
 		}
-		break
+		if len(longest) > 1 {
+			for _, hook := range r.AmbiguityHooks {
+				err = hook(ctx, longest)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
 	}
 	if subTree == nil {
+		if failure.location != nil {
+			return nil, NewErrUnexpectedToken(
+				r,
+				failure.location,
+				ShowInput(input[failure.location.Position-ctx.Location.Position:]),
+				NewErrExpected(failure.expected...),
+			)
+		}
 		return nil, NewErrUnexpectedToken(
 			r,
 			ctx.Location,
@@ -142,9 +254,11 @@ func (r *Either) Parse(ctx *Context, input []byte) (*Tree, error) {
 		Depth:  ctx.Depth,
 		Childs: []*Tree{subTree},
 		Data:   input[:subTree.Region.End-subTree.Region.Start],
+		Value:  subTree.Value,
+		action: subTree.action,
 	}
-	for _, hook := range r.Hooks {
-		hook(ctx, tree)
+	if err := runHooks(ctx, r, tree, r.Hooks); err != nil {
+		return nil, err
 	}
 	return tree, nil
 }
@@ -160,27 +274,46 @@ func (r *Either) Add(rule ...Rule) {
 
 // NewEither constructs *Either Rule.
 // Valid Either could be constructed with >=2 rules.
+// Accepts RuleParseHook, RuleEnterHook and RuleAmbiguityHook in addition to rules.
 func NewEither(name string, rulesOrHooks ...interface{}) *Either {
 	rules := []Rule{}
 	hooks := []RuleParseHook{}
+	ambiguityHooks := []RuleAmbiguityHook{}
+	var enterHooks []RuleEnterHook
 	for _, ruleOrHook := range rulesOrHooks {
 		switch v := ruleOrHook.(type) {
 		case Rule:
 			rules = append(rules, v)
 		case RuleParseHook:
 			hooks = append(hooks, v)
+		case RuleAmbiguityHook:
+			ambiguityHooks = append(ambiguityHooks, v)
+		case RuleEnterHook:
+			enterHooks = append(enterHooks, v)
 		default:
 			panic(fmt.Sprintf("unsupported type %T", ruleOrHook))
 		}
 	}
 	return &Either{
-		name:  name,
-		Rules: rules,
-		Hooks: hooks,
+		name:           name,
+		Rules:          rules,
+		Hooks:          hooks,
+		EnterHooks:     enterHooks,
+		AmbiguityHooks: ambiguityHooks,
 	}
 }
 
+// NewEitherLongest constructs *Either Rule which
+// matches the longest of the rules.
+// Accepts RuleAmbiguityHook in addition to rules and hooks.
+func NewEitherLongest(name string, rulesOrHooks ...interface{}) *Either {
+	r := NewEither(name, rulesOrHooks...)
+	r.Longest = true
+	return r
+}
+
 // NewASCIIRange constructs *Either(Terminal, ...) Rule using specified ASCII range.
+// Consider NewRuneRange which is faster and works with any unicode range.
 func NewASCIIRange(name string, from byte, to byte, hooks ...RuleParseHook) *Either {
 	if from > to {
 		panic(fmt.Errorf(
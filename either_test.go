@@ -170,6 +170,17 @@ func TestEitherGetParameters(t *testing.T) {
 				"name": "sample either",
 			},
 		},
+		{
+			NewEitherLongest(
+				"sample either",
+				newTestRuleFinite("foo"),
+				newTestRuleFinite("bar"),
+			),
+			RuleParameters{
+				"name":    "sample either",
+				"longest": true,
+			},
+		},
 	}
 	for k, sample := range samples {
 		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
@@ -266,10 +277,10 @@ func TestEither(t *testing.T) {
 				),
 				&Location{Path: DefaultParserPath},
 				[]byte("4"),
-				NewErrUnexpectedToken(
+				NewErrExpected(
+					NewTerminal("one", "1"),
+					NewTerminal("two", "2"),
 					NewTerminal("three", "3"),
-					&Location{Path: DefaultParserPath},
-					[]byte("4"),
 				),
 			),
 			DefaultParser,
@@ -436,6 +447,96 @@ func TestEither(t *testing.T) {
 	}
 }
 
+func TestEitherLongest(t *testing.T) {
+	var (
+		ambiguous    [][]string
+		errAmbiguous = fmt.Errorf("ambiguous")
+	)
+	ambiguity := func(ctx *Context, trees []*Tree) error {
+		names := make([]string, len(trees))
+		for k, tree := range trees {
+			names[k] = tree.Rule.Name()
+		}
+		ambiguous = append(ambiguous, names)
+		return nil
+	}
+	operator := NewEitherLongest(
+		"operator",
+		NewTerminal("<", "<"),
+		NewTerminal("<=", "<="),
+		NewTerminal("<<", "<<"),
+		NewTerminal("<<=", "<<="),
+		ambiguity,
+	)
+	identifier := NewEitherLongest(
+		"identifier",
+		NewKeywords("keyword", []string{"if", "else"}),
+		NewRegexp("name", "^[a-z]+"),
+		ambiguity,
+	)
+	strict := NewEitherLongest(
+		"strict",
+		NewTerminal("a", "a"),
+		NewRegexp("letter", "^[a-z]"),
+		func(ctx *Context, trees []*Tree) error {
+			return errAmbiguous
+		},
+	)
+
+	samples := []struct {
+		text      string
+		rule      Rule
+		match     string
+		ambiguous [][]string
+		err       error
+	}{
+		{"<", operator, "<", nil, nil},
+		{"<=", operator, "<=", nil, nil},
+		{"<<", operator, "<<", nil, nil},
+		{"<<=", operator, "<<=", nil, nil},
+		{"if", identifier, "keyword", [][]string{{"keyword", "name"}}, nil},
+		{"iff", identifier, "name", nil, nil},
+		{"b", strict, "letter", nil, nil},
+		{
+			"a",
+			strict,
+			"",
+			nil,
+			errAmbiguous,
+		},
+		{
+			">",
+			operator,
+			"",
+			nil,
+			NewErrUnexpectedToken(
+				operator,
+				&Location{Path: DefaultParserPath},
+				[]byte(">"),
+				NewErrExpected(
+					NewTerminal("<", "<"),
+					NewTerminal("<=", "<="),
+					NewTerminal("<<", "<<"),
+					NewTerminal("<<=", "<<="),
+				),
+			),
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			ambiguous = nil
+			msg := spew.Sdump(k, sample.text)
+			tree, err := Parse(sample.rule, []byte(sample.text))
+			assert.EqualValues(t, sample.err, err, msg)
+			assert.Equal(t, sample.ambiguous, ambiguous, msg)
+			if sample.err != nil {
+				return
+			}
+			assert.Equal(t, sample.match, tree.Childs[0].Rule.Name(), msg)
+		})
+	}
+}
+
 func TestASCIIRange(t *testing.T) {
 	samples := []struct {
 		text   string
@@ -574,3 +675,52 @@ func TestASCIIRange(t *testing.T) {
 		})
 	}
 }
+
+func TestEitherExpected(t *testing.T) {
+	var (
+		number     = NewRegexp("number", "^[0-9]+")
+		identifier = NewRegexp("identifier", "^[a-z]+")
+		keyword    = NewNot("not keyword", NewTerminal("if", "if"))
+		value      = NewEither(
+			"value",
+			number,
+			NewChain("group", NewTerminal("(", "("), number, NewTerminal(")", ")")),
+			NewChain("name", keyword, identifier),
+		)
+	)
+
+	samples := []struct {
+		text     string
+		memoize  bool
+		position int
+		expected string
+	}{
+		{"?", false, 0, `expected one of: number, "(", identifier`},
+		{"(1", false, 2, `expected ")"`},
+		{"(1]", true, 2, `expected ")"`},
+		{"(?)", false, 1, `expected number`},
+		{"if", false, 0, `expected one of: number, "("`},
+		{"-", false, 0, `expected one of: number, "(", identifier`},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			for _, predict := range []bool{false, true} {
+				msg := spew.Sdump(k, sample, predict)
+				_, err := NewParser(
+					ParserOptionMemoize(sample.memoize),
+					ParserOptionPredict(predict),
+				).Parse(
+					value,
+					[]byte(sample.text),
+				)
+				assert.IsType(t, &ErrUnexpectedToken{}, err, msg)
+				if err == nil {
+					continue
+				}
+				unexpected := err.(*ErrUnexpectedToken)
+				assert.Equal(t, sample.position, unexpected.Location.Position, msg)
+				assert.Equal(t, sample.expected, unexpected.Inner[0].Error(), msg)
+			}
+		})
+	}
+}
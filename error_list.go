@@ -0,0 +1,108 @@
+package parse
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ErrorList is a list of errors collected during the parse.
+// It could be sorted by Location of the errors (see ErrorLocation),
+// errors without Location are placed after others.
+// The zero value is an empty ErrorList ready to use.
+type ErrorList []error
+
+// Add appends err to the list, nil errors are ignored.
+// ErrorList added to the list is flattened.
+func (l *ErrorList) Add(err error) {
+	switch v := err.(type) {
+	case nil:
+	case ErrorList:
+		for _, err := range v {
+			l.Add(err)
+		}
+	default:
+		*l = append(*l, err)
+	}
+}
+
+// Len implements sort.Interface.
+func (l ErrorList) Len() int {
+	return len(l)
+}
+
+// Swap implements sort.Interface.
+func (l ErrorList) Swap(i, j int) {
+	l[i], l[j] = l[j], l[i]
+}
+
+// Less implements sort.Interface,
+// it compares errors by path, line, column & position.
+func (l ErrorList) Less(i, j int) bool {
+	a, b := ErrorLocation(l[i]), ErrorLocation(l[j])
+	switch {
+	case a == nil || b == nil:
+		return a != nil && b == nil
+	case a.Path != b.Path:
+		return a.Path < b.Path
+	case a.Line != b.Line:
+		return a.Line < b.Line
+	case a.Column != b.Column:
+		return a.Column < b.Column
+	default:
+		return a.Position < b.Position
+	}
+}
+
+// Sort sorts the list by Location of the errors,
+// order of errors with the same Location is preserved.
+func (l ErrorList) Sort() {
+	sort.Stable(l)
+}
+
+// Error returns all errors from the list, one per line.
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for k, err := range l {
+		msgs[k] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Err returns an error equivalent to this ErrorList,
+// it returns nil if the list is empty.
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// Unwrap returns errors from the list,
+// so they could be matched with errors.Is & errors.As.
+func (l ErrorList) Unwrap() []error {
+	return l
+}
+
+//
+
+// ErrorLocation returns a Location of the input where
+// err was found or nil if err does not carry a Location.
+func ErrorLocation(err error) *Location {
+	for err != nil {
+		switch v := err.(type) {
+		case *ErrUnexpectedToken:
+			return v.Location
+		case *ErrUnexpectedEOF:
+			return v.Location
+		case *ErrBoundIncomplete:
+			return v.Location
+		case *ErrNestingTooDeep:
+			return v.Location
+		case *ErrGrammar:
+			return v.Location
+		}
+		err = errors.Unwrap(err)
+	}
+	return nil
+}
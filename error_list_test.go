@@ -0,0 +1,66 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorList(t *testing.T) {
+	var (
+		rule = NewTerminal("x", "x")
+		at   = func(path string, line, column, position int) *Location {
+			return &Location{Path: path, Line: line, Column: column, Position: position}
+		}
+		second  = NewErrUnexpectedToken(rule, at("a", 1, 0, 5), []byte("y"))
+		first   = NewErrUnexpectedEOF(rule, at("a", 0, 3, 3))
+		other   = NewErrUnexpectedToken(rule, at("b", 0, 0, 0), []byte("z"))
+		unknown = fmt.Errorf("no location")
+	)
+
+	var l ErrorList
+	assert.Nil(t, l.Err())
+	assert.Equal(t, "", l.Error())
+
+	l.Add(nil)
+	l.Add(unknown)
+	l.Add(second)
+	l.Add(ErrorList{other, first})
+	assert.Len(t, l, 4)
+
+	l.Sort()
+	assert.Equal(t, ErrorList{first, second, other, unknown}, l)
+	assert.Equal(
+		t,
+		first.Error()+"\n"+second.Error()+"\n"+other.Error()+"\n"+unknown.Error(),
+		l.Err().Error(),
+	)
+
+	var eof *ErrUnexpectedEOF
+	assert.True(t, errors.As(l.Err(), &eof))
+	assert.Equal(t, first, eof)
+	assert.True(t, errors.Is(l.Err(), unknown))
+}
+
+func TestErrorLocation(t *testing.T) {
+	loc := &Location{Path: "file", Line: 1, Column: 2, Position: 10}
+	samples := []struct {
+		err error
+		loc *Location
+	}{
+		{NewErrUnexpectedToken(nil, loc, nil), loc},
+		{NewErrUnexpectedEOF(nil, loc), loc},
+		{NewErrBoundIncomplete(nil, nil, loc), loc},
+		{NewErrNestingTooDeep(loc, 1), loc},
+		{fmt.Errorf("wrapped: %w", NewErrGrammar(loc, nil)), loc},
+		{fmt.Errorf("no location"), nil},
+		{nil, nil},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			assert.Equal(t, sample.loc, ErrorLocation(sample.err))
+		})
+	}
+}
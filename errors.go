@@ -3,12 +3,16 @@ package parse
 import (
 	e "errors"
 	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
 )
 
 var (
 	ErrStopIteration = e.New("Stop iteration")
 	ErrSkipBranch    = e.New("Skip branch")
 	ErrSkipRule      = e.New("Skip rule")
+	ErrLeftRecursion = e.New("Left recursion")
 )
 
 // ErrBoundIncomplete is an error which mean
@@ -93,7 +97,9 @@ type ErrUnexpectedToken struct {
 func (e *ErrUnexpectedToken) Error() string {
 	innerErrs := ""
 	for _, innerErr := range e.Inner {
-		innerErrs = ": " + innerErr.Error()
+		if innerErr != nil {
+			innerErrs += ": " + innerErr.Error()
+		}
 	}
 
 	return fmt.Sprintf(
@@ -105,6 +111,11 @@ func (e *ErrUnexpectedToken) Error() string {
 	)
 }
 
+// Unwrap returns the inner errors.
+func (e *ErrUnexpectedToken) Unwrap() []error {
+	return e.Inner
+}
+
 // NewErrUnexpectedToken constructs new ErrUnexpectedToken.
 func NewErrUnexpectedToken(r Rule, l *Location, token []byte, inner ...error) error {
 	return &ErrUnexpectedToken{
@@ -165,3 +176,236 @@ func (e *ErrEmptyRule) Error() string {
 func NewErrEmptyRule(rule Rule, inside Rule) error {
 	return &ErrEmptyRule{rule, inside}
 }
+
+//
+
+// ErrGrammar is an error which mean
+// that textual grammar definition is malformed.
+type ErrGrammar struct {
+	Location *Location
+	Err      error
+}
+
+func (e *ErrGrammar) Error() string {
+	return fmt.Sprintf(
+		"Grammar error at %q: %s",
+		e.Location,
+		e.Err,
+	)
+}
+
+// Unwrap returns the underlying error.
+func (e *ErrGrammar) Unwrap() error {
+	return e.Err
+}
+
+// NewErrGrammar constructs new ErrGrammar.
+func NewErrGrammar(l *Location, err error) error {
+	return &ErrGrammar{l, err}
+}
+
+//
+
+// ErrRuleUndefined is an error which mean
+// that a Rule referenced by name is not defined.
+type ErrRuleUndefined struct {
+	Name string
+}
+
+func (e *ErrRuleUndefined) Error() string {
+	return fmt.Sprintf(
+		"Rule %q is referenced but not defined",
+		e.Name,
+	)
+}
+
+// NewErrRuleUndefined constructs new ErrRuleUndefined.
+func NewErrRuleUndefined(name string) error {
+	return &ErrRuleUndefined{name}
+}
+
+//
+
+// ErrRuleDuplicate is an error which mean
+// that a Rule with the same name is defined more than once.
+type ErrRuleDuplicate struct {
+	Name string
+}
+
+func (e *ErrRuleDuplicate) Error() string {
+	return fmt.Sprintf(
+		"Rule %q is defined more than once",
+		e.Name,
+	)
+}
+
+// NewErrRuleDuplicate constructs new ErrRuleDuplicate.
+func NewErrRuleDuplicate(name string) error {
+	return &ErrRuleDuplicate{name}
+}
+
+//
+
+// ErrRuleUnreachable is an error which mean
+// that a Rule could not be reached from the start rule.
+type ErrRuleUnreachable struct {
+	Name string
+}
+
+func (e *ErrRuleUnreachable) Error() string {
+	return fmt.Sprintf(
+		"Rule %q is not reachable from the start rule",
+		e.Name,
+	)
+}
+
+// NewErrRuleUnreachable constructs new ErrRuleUnreachable.
+func NewErrRuleUnreachable(name string) error {
+	return &ErrRuleUnreachable{name}
+}
+
+//
+
+// ErrRuleNil is an error which mean
+// that a Rule contains nil instead of a child Rule.
+type ErrRuleNil struct {
+	Inside Rule
+}
+
+func (e *ErrRuleNil) Error() string {
+	return fmt.Sprintf(
+		"Nil rule inside '%T' = '%s' rule",
+		e.Inside,
+		e.Inside.Name(),
+	)
+}
+
+// NewErrRuleNil constructs new ErrRuleNil.
+func NewErrRuleNil(inside Rule) error {
+	return &ErrRuleNil{inside}
+}
+
+//
+
+// ErrRuleCycle is an error which mean
+// that a Rule is a part of the cycle which
+// never terminates, so it could not match any input.
+type ErrRuleCycle struct {
+	Rule Rule
+}
+
+func (e *ErrRuleCycle) Error() string {
+	return fmt.Sprintf(
+		"Rule '%T' = '%s' is a part of the cycle which never terminates",
+		e.Rule,
+		e.Rule.Name(),
+	)
+}
+
+// NewErrRuleCycle constructs new ErrRuleCycle.
+func NewErrRuleCycle(rule Rule) error {
+	return &ErrRuleCycle{rule}
+}
+
+// ErrRepetitionNullable is an error which mean
+// that a Rule repeats other Rule which could match
+// an empty input, so it could loop without consuming input.
+type ErrRepetitionNullable struct {
+	Rule Rule
+}
+
+func (e *ErrRepetitionNullable) Error() string {
+	return fmt.Sprintf(
+		"Rule '%T' = '%s' repeats a rule which could match an empty input",
+		e.Rule,
+		e.Rule.Name(),
+	)
+}
+
+// NewErrRepetitionNullable constructs new ErrRepetitionNullable.
+func NewErrRepetitionNullable(rule Rule) error {
+	return &ErrRepetitionNullable{rule}
+}
+
+//
+
+// ErrExpected is an error which mean
+// that one of the Expected rules was expected
+// at the position where the parse failed.
+type ErrExpected struct {
+	Expected Rules
+}
+
+func (e *ErrExpected) Error() string {
+	var (
+		names = []string{}
+		seen  = map[string]bool{}
+	)
+	for _, rule := range e.Expected {
+		name := rule.Name()
+		if terminal, ok := rule.(*Terminal); ok {
+			name = strconv.Quote(string(terminal.Value))
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if len(names) == 1 {
+		return "expected " + names[0]
+	}
+	return "expected one of: " + strings.Join(names, ", ")
+}
+
+// NewErrExpected constructs new ErrExpected.
+func NewErrExpected(expected ...Rule) error {
+	return &ErrExpected{expected}
+}
+
+//
+
+// ErrFatal is an error which mean
+// that the parse should be stopped.
+// Hooks wrap errors with it to fail the whole parse
+// instead of the current Rule only.
+type ErrFatal struct {
+	Err error
+}
+
+func (e *ErrFatal) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ErrFatal) Unwrap() error {
+	return e.Err
+}
+
+// NewErrFatal constructs new ErrFatal.
+func NewErrFatal(err error) error {
+	return &ErrFatal{err}
+}
+
+//
+
+// ErrValueType is an error which mean
+// that Tree.Value has unexpected type.
+type ErrValueType struct {
+	Tree *Tree
+	Want reflect.Type
+}
+
+func (e *ErrValueType) Error() string {
+	return fmt.Sprintf(
+		"Unexpected value type '%T' of '%s' rule at %q, want '%s'",
+		e.Tree.Value,
+		e.Tree.Name(),
+		e.Tree.Location,
+		e.Want,
+	)
+}
+
+// NewErrValueType constructs new ErrValueType.
+func NewErrValueType(tree *Tree, want reflect.Type) error {
+	return &ErrValueType{tree, want}
+}
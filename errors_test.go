@@ -0,0 +1,29 @@
+package parse
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrUnexpectedTokenInner(t *testing.T) {
+	var (
+		reason = errors.New("reason")
+		cause  = errors.New("cause")
+		err    = NewErrUnexpectedToken(
+			NewTerminal("x", "x"),
+			&Location{},
+			[]byte("y"),
+			reason,
+			cause,
+		)
+	)
+	assert.Equal(
+		t,
+		`Unexpected token 'y' at ":1:1" while applying 'x' rule: reason: cause`,
+		err.Error(),
+	)
+	assert.True(t, errors.Is(err, reason))
+	assert.True(t, errors.Is(err, cause))
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	. "github.com/corpix/parse"
+)
+
+var (
+	expression *Expression
+)
+
+func init() {
+	number := NewRegexp(
+		"number",
+		"^[0-9]+",
+		Action(func(ctx *Context, tree *Tree, values []int) (int, error) {
+			return strconv.Atoi(string(tree.Data))
+		}),
+	)
+
+	expression = NewExpression(
+		"expression",
+		nil,
+		[]*ExpressionOperator{
+			NewExpressionInfix(NewTerminal("+", "+"), 1, ExpressionLeft),
+			NewExpressionInfix(NewTerminal("-", "-"), 1, ExpressionLeft),
+			NewExpressionInfix(NewTerminal("*", "*"), 2, ExpressionLeft),
+			NewExpressionInfix(NewTerminal("/", "/"), 2, ExpressionLeft),
+		},
+		Action(func(ctx *Context, tree *Tree, values []int) (int, error) {
+			if len(values) == 1 {
+				return values[0], nil
+			}
+			lhs, rhs := values[0], values[2]
+			switch string(tree.Childs[1].Data) {
+			case "+":
+				return lhs + rhs, nil
+			case "-":
+				return lhs - rhs, nil
+			case "*":
+				return lhs * rhs, nil
+			default:
+				if rhs == 0 {
+					return 0, NewErrFatal(fmt.Errorf("division by zero at %s", tree.Location))
+				}
+				return lhs / rhs, nil
+			}
+		}),
+	)
+
+	expression.Operand = NewEither(
+		"operand",
+		number,
+		NewChain(
+			"group",
+			NewTerminal("leftBracket", "("),
+			expression,
+			NewTerminal("rightBracket", ")"),
+			Action(func(ctx *Context, tree *Tree, values []int) (int, error) {
+				return values[1], nil
+			}),
+		),
+	)
+}
+
+func main() {
+	value, err := Eval[int](expression, []byte("5+(3*2)"))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(value)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	. "github.com/corpix/parse"
+)
+
+var (
+	expression *Expression
+)
+
+func init() {
+	number := NewRegexp(
+		"number",
+		"^[0-9]+(\\.[0-9]+)?",
+		func(ctx *Context, tree *Tree) error {
+			value, err := strconv.ParseFloat(string(tree.Data), 64)
+			tree.Value = value
+			return err
+		},
+	)
+
+	expression = NewExpression(
+		"expression",
+		nil,
+		[]*ExpressionOperator{
+			NewExpressionInfix(NewTerminal("+", "+"), 1, ExpressionLeft),
+			NewExpressionInfix(NewTerminal("-", "-"), 1, ExpressionLeft),
+			NewExpressionInfix(NewTerminal("*", "*"), 2, ExpressionLeft),
+			NewExpressionInfix(NewTerminal("/", "/"), 2, ExpressionLeft),
+			NewExpressionPrefix(NewTerminal("-", "-"), 3),
+			NewExpressionInfix(NewTerminal("^", "^"), 4, ExpressionRight),
+		},
+		func(ctx *Context, tree *Tree) error {
+			switch len(tree.Childs) {
+			case 1:
+				tree.Value = tree.Childs[0].Value
+			case 2:
+				tree.Value = -tree.Childs[1].Value.(float64)
+			case 3:
+				lhs := tree.Childs[0].Value.(float64)
+				rhs := tree.Childs[2].Value.(float64)
+				switch string(tree.Childs[1].Data) {
+				case "+":
+					tree.Value = lhs + rhs
+				case "-":
+					tree.Value = lhs - rhs
+				case "*":
+					tree.Value = lhs * rhs
+				case "/":
+					tree.Value = lhs / rhs
+				case "^":
+					tree.Value = math.Pow(lhs, rhs)
+				}
+			}
+			return nil
+		},
+	)
+
+	expression.Operand = NewEither(
+		"operand",
+		number,
+		NewChain(
+			"group",
+			NewTerminal("leftBracket", "("),
+			expression,
+			NewTerminal("rightBracket", ")"),
+		),
+		func(ctx *Context, tree *Tree) error {
+			child := tree.Childs[0]
+			if len(child.Childs) == 3 { // group
+				child = child.Childs[1]
+			}
+			tree.Value = child.Value
+			return nil
+		},
+	)
+}
+
+func main() {
+	p := NewParser(ParserOptionPath("calculator-precedence.go"))
+	tree, err := p.Parse(
+		expression,
+		[]byte("5+(3*2)^2-10/4"),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(tree.Value)
+}
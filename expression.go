@@ -0,0 +1,445 @@
+package parse
+
+import (
+	"fmt"
+)
+
+var _ Rule = new(Expression)
+
+var ErrExpressionNonAssociative = fmt.Errorf("non-associative operator could not be chained")
+
+// ExpressionOperatorKind is a kind of the operator
+// which defines its position relative to operands.
+type ExpressionOperatorKind int
+
+const (
+	// ExpressionPrefix operator precedes the operand, like `-a`.
+	ExpressionPrefix ExpressionOperatorKind = iota
+	// ExpressionInfix operator is between two operands, like `a + b`.
+	ExpressionInfix
+	// ExpressionPostfix operator follows the operand, like `a!`.
+	ExpressionPostfix
+	// ExpressionTernary operator has three operands, like `a ? b : c`.
+	ExpressionTernary
+)
+
+func (k ExpressionOperatorKind) String() string {
+	switch k {
+	case ExpressionPrefix:
+		return "prefix"
+	case ExpressionInfix:
+		return "infix"
+	case ExpressionPostfix:
+		return "postfix"
+	case ExpressionTernary:
+		return "ternary"
+	default:
+		return fmt.Sprintf("ExpressionOperatorKind(%d)", int(k))
+	}
+}
+
+// ExpressionAssociativity defines how infix operators
+// with the same precedence are grouped.
+type ExpressionAssociativity int
+
+const (
+	// ExpressionLeft groups `a - b - c` as `(a - b) - c`.
+	ExpressionLeft ExpressionAssociativity = iota
+	// ExpressionRight groups `a ^ b ^ c` as `a ^ (b ^ c)`.
+	ExpressionRight
+	// ExpressionNonAssociative makes `a < b < c` an error.
+	ExpressionNonAssociative
+)
+
+// ExpressionOperator describes an operator of the Expression.
+// Rule matches the operator token, Else matches the second
+// token of the ternary operator (like `:` in `a ? b : c`).
+// Operators with higher Precedence bind tighter.
+type ExpressionOperator struct {
+	Kind          ExpressionOperatorKind
+	Rule          Rule
+	Else          Rule
+	Precedence    int
+	Associativity ExpressionAssociativity
+}
+
+// Expression is a Rule which matches operands combined
+// with prefix, infix, postfix and ternary operators,
+// producing a Tree nested according to operators precedence
+// and associativity (using precedence climbing).
+//
+// Every operator application yields a Tree with Expression as a Rule
+// and childs in the order they appear in the input:
+//
+//	prefix  [operator, operand]
+//	infix   [lhs, operator, rhs]
+//	postfix [operand, operator]
+//	ternary [condition, operator, then, else operator, else]
+//
+// Where operands are Operand trees or nested Expression trees.
+// If input contains a single operand then it is wrapped
+// into Expression Tree with a single child and its Tree.Value.
+// Hooks are called for every Expression Tree, innermost first.
+//
+// When several operators match at the same position
+// the longest wins, then the first one in Operators.
+type Expression struct {
+	name       string
+	Operand    Rule
+	Operators  []*ExpressionOperator
+	Hooks      []RuleParseHook
+	EnterHooks []RuleEnterHook
+}
+
+// Name indicates the name which was given to the rule
+// on creation. Name could be not unique.
+func (r *Expression) Name() string {
+	return r.name
+}
+
+// Show this node as a string.
+// You should provide childs as string
+// to this function, it does not care
+// about nesting in a tree, it only shows
+// string representation of itself.
+func (r *Expression) Show(childs string) string {
+	return RuleShow(
+		r,
+		r.GetParameters().String(),
+		childs,
+	)
+}
+
+// String returns rule as a string,
+// resolving recursion with `<circular>` placeholder.
+func (r *Expression) String() string {
+	return TreerString(r)
+}
+
+// GetChilds returns a slice of Rule which is
+// children for current Rule.
+func (r *Expression) GetChilds() Treers {
+	treers := Treers{r.Operand}
+	for _, operator := range r.Operators {
+		treers = append(treers, operator.Rule)
+		if operator.Kind == ExpressionTernary {
+			treers = append(treers, operator.Else)
+		}
+	}
+	return treers
+}
+
+//
+
+// GetParameters returns a KV rule parameters.
+func (r *Expression) GetParameters() RuleParameters {
+	return RuleParameters{
+		"name": r.name,
+	}
+}
+
+// IsFinite returns true if this rule is
+// not a wrapper for other rules.
+func (r *Expression) IsFinite() bool {
+	return false
+}
+
+// Parse consumes some bytes from input & emits a Tree
+// using settings defined during creation of the concrete Rule type.
+// May return an error if something goes wrong, should provide some
+// location information to the user which points to position in input.
+func (r *Expression) Parse(ctx *Context, input []byte) (*Tree, error) {
+	if err := runEnterHooks(ctx, r, input, r.EnterHooks); err != nil {
+		return nil, err
+	}
+
+	if r.Operand == nil {
+		return nil, NewErrEmptyRule(r, ctx.Rule)
+	}
+	for _, operator := range r.Operators {
+		if operator.Rule == nil || (operator.Kind == ExpressionTernary && operator.Else == nil) {
+			return nil, NewErrEmptyRule(r, ctx.Rule)
+		}
+	}
+
+	tree, err := r.expression(ctx, input, ctx.Location.Position, 0, 1)
+	if err != nil {
+		if e, ok := err.(*ErrUnexpectedToken); ok && e.Rule == r {
+			return nil, err
+		}
+		if isErrNoMatch(err) {
+			return nil, NewErrUnexpectedToken(r, ctx.Location, ShowInput(input), err)
+		}
+		return nil, err
+	}
+	if tree.Rule != r {
+		return r.node(ctx, input, tree)
+	}
+	return tree, nil
+}
+
+// expression parses operands & operators at position pos
+// which bind with precedence >= min.
+func (r *Expression) expression(ctx *Context, input []byte, pos int, min int, level int) (*Tree, error) {
+	depth := ctx.Depth + level
+	if depth > ctx.Parser.MaxDepth {
+		return nil, NewErrNestingTooDeep(ctx.Location, depth)
+	}
+
+	var (
+		lhs      *Tree
+		operand  *Tree
+		operator *ExpressionOperator
+		op       *Tree
+		err      error
+	)
+
+	// NOTE: prefix operator is allowed in any operand position,
+	// its precedence limits only the operand it applies to.
+	operator, op, err = r.operator(ctx, input, pos, ExpressionPrefix, 0)
+	if err != nil {
+		return nil, err
+	}
+	if operator != nil {
+		operand, err = r.expression(ctx, input, op.Region.End, operator.Precedence, level+1)
+		if err != nil {
+			return nil, err
+		}
+		lhs, err = r.node(ctx, input, op, operand)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		lhs, err = r.parse(ctx, r.Operand, input, pos)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		operator, op, err = r.operator(ctx, input, lhs.Region.End, ExpressionPostfix, min)
+		if err != nil {
+			return nil, err
+		}
+		if operator != nil {
+			lhs, err = r.node(ctx, input, lhs, op)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		operator, op, err = r.operator(ctx, input, lhs.Region.End, ExpressionInfix, min)
+		if err != nil {
+			return nil, err
+		}
+		if operator == nil {
+			break
+		}
+
+		switch operator.Kind {
+		case ExpressionTernary:
+			var (
+				then, elseOp, elseTree *Tree
+			)
+			then, err = r.expression(ctx, input, op.Region.End, 0, level+1)
+			if err != nil {
+				return nil, err
+			}
+			elseOp, err = r.parse(ctx, operator.Else, input, then.Region.End)
+			if err != nil {
+				return nil, err
+			}
+			elseTree, err = r.expression(ctx, input, elseOp.Region.End, operator.Precedence, level+1)
+			if err != nil {
+				return nil, err
+			}
+			lhs, err = r.node(ctx, input, lhs, op, then, elseOp, elseTree)
+		default:
+			next := operator.Precedence + 1
+			if operator.Associativity == ExpressionRight {
+				next = operator.Precedence
+			}
+			var rhs *Tree
+			rhs, err = r.expression(ctx, input, op.Region.End, next, level+1)
+			if err != nil {
+				return nil, err
+			}
+			lhs, err = r.node(ctx, input, lhs, op, rhs)
+			if err == nil && operator.Associativity == ExpressionNonAssociative {
+				err = r.nonAssociative(ctx, input, lhs.Region.End, operator.Precedence)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lhs, nil
+}
+
+// operator matches the operator of the kind with precedence >= min at position pos,
+// ternary operators are matched with infix kind, empty matches are ignored.
+// Returns nil operator if nothing matched.
+func (r *Expression) operator(ctx *Context, input []byte, pos int, kind ExpressionOperatorKind, min int) (*ExpressionOperator, *Tree, error) {
+	var (
+		operator *ExpressionOperator
+		op       *Tree
+	)
+	for _, candidate := range r.Operators {
+		switch {
+		case candidate.Precedence < min:
+			continue
+		case candidate.Kind == kind:
+		case candidate.Kind == ExpressionTernary && kind == ExpressionInfix:
+		default:
+			continue
+		}
+
+		tree, err := r.parse(ctx, candidate.Rule, input, pos)
+		if err != nil {
+			if isErrNoMatch(err) {
+				continue
+			}
+			return nil, nil, err
+		}
+		if tree.Region.End == pos { // NOTE: empty operator would never stop
+			continue
+		}
+		if op == nil || tree.Region.End > op.Region.End {
+			operator, op = candidate, tree
+		}
+	}
+	return operator, op, nil
+}
+
+// nonAssociative returns an error if non-associative operator
+// with the same precedence follows at position pos.
+func (r *Expression) nonAssociative(ctx *Context, input []byte, pos int, precedence int) error {
+	operator, op, err := r.operator(ctx, input, pos, ExpressionInfix, precedence)
+	if err != nil || operator == nil {
+		return err
+	}
+	if operator.Precedence != precedence || operator.Associativity != ExpressionNonAssociative {
+		return nil
+	}
+	return NewErrUnexpectedToken(
+		r,
+		op.Location,
+		ShowInput(input[pos-ctx.Location.Position:]),
+		ErrExpressionNonAssociative,
+	)
+}
+
+// parse applies sub-rule at the position pos,
+// state is rolled back if sub-rule fails.
+func (r *Expression) parse(ctx *Context, rule Rule, input []byte, pos int) (*Tree, error) {
+	line, col := ctx.Parser.Locate(pos)
+	snapshot := ctx.snapshot()
+	tree, err := ctx.Parser.ParseRule(
+		rule,
+		&Context{
+			Rule:   r,
+			Parser: ctx.Parser,
+			Location: &Location{
+				Path:     ctx.Location.Path,
+				Position: pos,
+				Line:     line,
+				Column:   col,
+			},
+			Depth: ctx.Depth + 1,
+			State: ctx.State,
+		},
+		input[pos-ctx.Location.Position:],
+	)
+	if err != nil {
+		snapshot.restore()
+	}
+	if err == ErrSkipRule {
+		return nil, NewErrUnexpectedEOF(rule, &Location{
+			Path:     ctx.Location.Path,
+			Position: pos,
+			Line:     line,
+			Column:   col,
+		})
+	}
+	return tree, err
+}
+
+// node constructs Expression Tree from childs.
+func (r *Expression) node(ctx *Context, input []byte, childs ...*Tree) (*Tree, error) {
+	region := TreeRegion(childs...)
+	line, col := ctx.Parser.Locate(region.Start)
+	tree := &Tree{
+		Rule: r,
+		Location: &Location{
+			Path:     ctx.Location.Path,
+			Position: region.Start,
+			Line:     line,
+			Column:   col,
+		},
+		Region: region,
+		Depth:  ctx.Depth,
+		Childs: childs,
+		Data:   input[region.Start-ctx.Location.Position : region.End-ctx.Location.Position],
+	}
+	if len(childs) == 1 {
+		tree.Value = childs[0].Value
+		tree.action = childs[0].action
+	}
+	if err := runHooks(ctx, r, tree, r.Hooks); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+//
+
+// NewExpression constructs new *Expression.
+func NewExpression(name string, operand Rule, operators []*ExpressionOperator, hooks ...RuleParseHook) *Expression {
+	return &Expression{
+		name:      name,
+		Operand:   operand,
+		Operators: operators,
+		Hooks:     hooks,
+	}
+}
+
+// NewExpressionPrefix constructs new prefix *ExpressionOperator.
+func NewExpressionPrefix(rule Rule, precedence int) *ExpressionOperator {
+	return &ExpressionOperator{
+		Kind:       ExpressionPrefix,
+		Rule:       rule,
+		Precedence: precedence,
+	}
+}
+
+// NewExpressionInfix constructs new infix *ExpressionOperator.
+func NewExpressionInfix(rule Rule, precedence int, associativity ExpressionAssociativity) *ExpressionOperator {
+	return &ExpressionOperator{
+		Kind:          ExpressionInfix,
+		Rule:          rule,
+		Precedence:    precedence,
+		Associativity: associativity,
+	}
+}
+
+// NewExpressionPostfix constructs new postfix *ExpressionOperator.
+func NewExpressionPostfix(rule Rule, precedence int) *ExpressionOperator {
+	return &ExpressionOperator{
+		Kind:       ExpressionPostfix,
+		Rule:       rule,
+		Precedence: precedence,
+	}
+}
+
+// NewExpressionTernary constructs new right-associative
+// ternary *ExpressionOperator, like `a ? b : c`.
+func NewExpressionTernary(rule Rule, elseRule Rule, precedence int) *ExpressionOperator {
+	return &ExpressionOperator{
+		Kind:          ExpressionTernary,
+		Rule:          rule,
+		Else:          elseRule,
+		Precedence:    precedence,
+		Associativity: ExpressionRight,
+	}
+}
@@ -0,0 +1,152 @@
+package parse
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+// showExpression renders Expression tree as s-expression.
+func showExpression(t *Tree) string {
+	if _, ok := t.Rule.(*Expression); !ok {
+		return string(t.Data)
+	}
+	if len(t.Childs) == 1 {
+		return showExpression(t.Childs[0])
+	}
+	parts := make([]string, len(t.Childs))
+	for k, child := range t.Childs {
+		parts[k] = showExpression(child)
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+func newTestExpression() *Expression {
+	expression := NewExpression("expression", nil, nil)
+	expression.Operand = NewEither(
+		"operand",
+		NewRegexp("number", "^[0-9]+"),
+		NewChain(
+			"group",
+			NewTerminal("(", "("),
+			expression,
+			NewTerminal(")", ")"),
+		),
+	)
+	expression.Operators = []*ExpressionOperator{
+		NewExpressionTernary(NewTerminal("?", "?"), NewTerminal(":", ":"), 1),
+		NewExpressionInfix(NewTerminal("<", "<"), 2, ExpressionNonAssociative),
+		NewExpressionInfix(NewTerminal("<=", "<="), 2, ExpressionNonAssociative),
+		NewExpressionInfix(NewTerminal("+", "+"), 3, ExpressionLeft),
+		NewExpressionInfix(NewTerminal("-", "-"), 3, ExpressionLeft),
+		NewExpressionInfix(NewTerminal("*", "*"), 4, ExpressionLeft),
+		NewExpressionInfix(NewTerminal("/", "/"), 4, ExpressionLeft),
+		NewExpressionPrefix(NewTerminal("-", "-"), 5),
+		NewExpressionInfix(NewTerminal("^", "^"), 6, ExpressionRight),
+		NewExpressionPostfix(NewTerminal("!", "!"), 7),
+	}
+	return expression
+}
+
+func TestExpressionShow(t *testing.T) {
+	rule := NewExpression(
+		"expression",
+		NewTerminal("x", "x"),
+		[]*ExpressionOperator{
+			NewExpressionInfix(NewTerminal("+", "+"), 1, ExpressionLeft),
+			NewExpressionTernary(NewTerminal("?", "?"), NewTerminal(":", ":"), 0),
+		},
+	)
+	assert.Equal(t, "*parse.Expression(name: expression)(none)", rule.Show("none"))
+	assert.Equal(
+		t,
+		Treers{
+			NewTerminal("x", "x"),
+			NewTerminal("+", "+"),
+			NewTerminal("?", "?"),
+			NewTerminal(":", ":"),
+		},
+		rule.GetChilds(),
+	)
+	assert.False(t, rule.IsFinite())
+}
+
+func TestExpression(t *testing.T) {
+	expression := newTestExpression()
+	samples := []struct {
+		text string
+		show string
+		err  error
+	}{
+		{"1", "1", nil},
+		{"1+2", "(1 + 2)", nil},
+		{"1+2*3", "(1 + (2 * 3))", nil},
+		{"1*2+3", "((1 * 2) + 3)", nil},
+		{"1-2-3", "((1 - 2) - 3)", nil},
+		{"2^3^4", "(2 ^ (3 ^ 4))", nil},
+		{"-2^2", "(- (2 ^ 2))", nil},
+		{"2*-3", "(2 * (- 3))", nil},
+		{"--3", "(- (- 3))", nil},
+		{"3!+1", "((3 !) + 1)", nil},
+		{"-3!", "(- (3 !))", nil},
+		{"(1+2)*3", "((1+2) * 3)", nil},
+		{"1<=2", "(1 <= 2)", nil},
+		{"1<2?3:4", "((1 < 2) ? 3 : 4)", nil},
+		{"1?2:3?4:5", "(1 ? 2 : (3 ? 4 : 5))", nil},
+		{"1?2?3:4:5", "(1 ? (2 ? 3 : 4) : 5)", nil},
+		{
+			"1<2<3",
+			"",
+			NewErrUnexpectedToken(
+				expression,
+				&Location{Path: DefaultParserPath, Position: 3, Column: 3},
+				[]byte("<3"),
+				ErrExpressionNonAssociative,
+			),
+		},
+		{
+			"1+",
+			"",
+			NewErrUnexpectedToken(
+				expression,
+				&Location{Path: DefaultParserPath},
+				[]byte("1+"),
+				NewErrUnexpectedEOF(
+					expression.Operand,
+					&Location{Path: DefaultParserPath, Position: 2, Column: 1},
+				),
+			),
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample.text)
+			tree, err := Parse(expression, []byte(sample.text))
+			assert.EqualValues(t, sample.err, err, msg)
+			if sample.err != nil {
+				return
+			}
+			assert.Equal(t, sample.show, showExpression(tree), msg)
+			assert.Equal(t, expression, tree.Rule, msg)
+			assert.Equal(t, []byte(sample.text), tree.Data, msg)
+		})
+	}
+}
+
+func TestExpressionHooks(t *testing.T) {
+	expression := newTestExpression()
+	nodes := []string{}
+	expression.Hooks = append(expression.Hooks, func(ctx *Context, tree *Tree) error {
+		nodes = append(nodes, string(tree.Data))
+		return nil
+	})
+
+	_, err := Parse(expression, []byte("1+2*3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"2*3", "1+2*3"}, nodes)
+}
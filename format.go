@@ -0,0 +1,334 @@
+package parse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FormatEBNF prints a Rule graph as a named-production EBNF document
+// (ISO 14977 flavor) which could be loaded back with LoadEBNF.
+// Every non-finite Rule reachable from the rule becomes a production,
+// finite rules are inlined, recursion is resolved with production names.
+// Rules which have no EBNF equivalent are printed as special sequences `? ... ?`,
+// LoadEBNF loads them as unresolved Ref placeholders (which fail when applied)
+// except case-folding terminals which are loaded back as is.
+func FormatEBNF(rule Rule) string {
+	return formatGrammar(rule, formatEBNF)
+}
+
+// FormatABNF prints a Rule graph as a named-production ABNF document (RFC 5234).
+// Terminals are printed as case-sensitive strings (RFC 7405)
+// unless they are case-folding, rules which have no ABNF equivalent are printed as prose values `<...>`.
+func FormatABNF(rule Rule) string {
+	return formatGrammar(rule, formatABNF)
+}
+
+//
+
+// grammarFormat describes a notation of the grammar document.
+type grammarFormat struct {
+	name       func(name string) string
+	production func(name string, body string) string
+	chain      string
+	either     string
+	repetition func(expr string, times int, variadic bool) string
+	optional   func(expr string) string
+	group      func(expr string) string
+	terminal   func(value []byte, fold bool) string
+	regexp     func(expr string) string
+	special    func(text string) string
+}
+
+var (
+	formatEBNF = &grammarFormat{
+		name: func(name string) string {
+			return formatName(name, '_')
+		},
+		production: func(name string, body string) string {
+			return name + " = " + body + " ;"
+		},
+		chain:  " , ",
+		either: " | ",
+		repetition: func(expr string, times int, variadic bool) string {
+			switch {
+			case !variadic:
+				return fmt.Sprintf("%d * %s", times, expr)
+			case times == 0:
+				return "{ " + expr + " }"
+			case times == 1:
+				return expr + " , { " + expr + " }"
+			default:
+				return fmt.Sprintf("%d * %s , { %s }", times, expr, expr)
+			}
+		},
+		optional: func(expr string) string {
+			return "[ " + expr + " ]"
+		},
+		group: func(expr string) string {
+			return "( " + expr + " )"
+		},
+		terminal: func(value []byte, fold bool) string {
+			if fold {
+				return "? case-insensitive " + strings.ReplaceAll(strconv.Quote(string(value)), "?", `\x3f`) + " ?"
+			}
+			return strconv.Quote(string(value))
+		},
+		regexp: func(expr string) string {
+			return "/" + strings.ReplaceAll(expr, "/", `\/`) + "/"
+		},
+		special: func(text string) string {
+			return "? " + strings.ReplaceAll(text, "?", "_") + " ?"
+		},
+	}
+	formatABNF = &grammarFormat{
+		name: func(name string) string {
+			return formatName(name, '-')
+		},
+		production: func(name string, body string) string {
+			return name + " = " + body
+		},
+		chain:  " ",
+		either: " / ",
+		repetition: func(expr string, times int, variadic bool) string {
+			switch {
+			case !variadic:
+				return fmt.Sprintf("%d%s", times, expr)
+			case times == 0:
+				return "*" + expr
+			default:
+				return fmt.Sprintf("%d*%s", times, expr)
+			}
+		},
+		optional: func(expr string) string {
+			return "[" + expr + "]"
+		},
+		group: func(expr string) string {
+			return "(" + expr + ")"
+		},
+		terminal: func(value []byte, fold bool) string {
+			printable := true
+			for _, c := range value {
+				if c < 0x20 || c > 0x7e || c == '"' {
+					printable = false
+					break
+				}
+			}
+			switch {
+			case printable && fold:
+				return `"` + string(value) + `"`
+			case printable:
+				return `%s"` + string(value) + `"`
+			}
+			hex := make([]string, len(value))
+			for k, c := range value {
+				hex[k] = fmt.Sprintf("%02X", c)
+			}
+			return "%x" + strings.Join(hex, ".")
+		},
+		regexp: func(expr string) string {
+			return "<regexp " + strings.ReplaceAll(expr, ">", "_") + ">"
+		},
+		special: func(text string) string {
+			return "<" + strings.ReplaceAll(text, ">", "_") + ">"
+		},
+	}
+)
+
+// formatName converts a Rule name into identifier
+// replacing unsupported characters with replacement.
+func formatName(name string, replacement rune) string {
+	buf := make([]rune, 0, len(name))
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == replacement:
+			buf = append(buf, r)
+		default:
+			buf = append(buf, replacement)
+		}
+	}
+	if len(buf) == 0 {
+		return "rule"
+	}
+	if first, _ := utf8.DecodeRuneInString(string(buf)); !unicode.IsLetter(first) {
+		return "rule" + string(replacement) + string(buf)
+	}
+	return string(buf)
+}
+
+// ruleProductions walks the Rule graph from the root and returns
+// rules which should be a named productions in visiting order:
+// the root and every non-finite rule, Ref is replaced with its Target.
+// Each production gets a unique name made with the name function.
+func ruleProductions(root Rule, name func(string) string) (Rules, map[Rule]string) {
+	var (
+		rules = Rules{}
+		names = map[Rule]string{}
+		taken = map[string]bool{}
+		walk  func(rule Rule)
+	)
+	walk = func(rule Rule) {
+		rule = Unref(rule)
+		if rule == nil || isRefUnresolved(rule) {
+			return
+		}
+		if _, ok := names[rule]; ok {
+			return
+		}
+		if len(rules) > 0 && rule.IsFinite() {
+			return
+		}
+
+		n := name(rule.Name())
+		for k := 2; taken[n]; k++ {
+			n = name(fmt.Sprintf("%s-%d", rule.Name(), k))
+		}
+		names[rule] = n
+		taken[n] = true
+		rules = append(rules, rule)
+
+		for _, child := range rule.GetChilds() {
+			if r, ok := child.(Rule); ok {
+				walk(r)
+			}
+		}
+	}
+	walk(root)
+	return rules, names
+}
+
+// grammarFormatter formats productions of the Rule graph.
+type grammarFormatter struct {
+	format *grammarFormat
+	names  map[Rule]string
+}
+
+func formatGrammar(rule Rule, format *grammarFormat) string {
+	rules, names := ruleProductions(rule, format.name)
+	f := &grammarFormatter{
+		format: format,
+		names:  names,
+	}
+
+	productions := make([]string, len(rules))
+	for k, r := range rules {
+		productions[k] = format.production(names[r], f.body(r))
+	}
+	return strings.Join(productions, newLine) + newLine
+}
+
+// body formats the production body for the non-finite rule.
+func (f *grammarFormatter) body(rule Rule) string {
+	switch r := rule.(type) {
+	case *Chain:
+		return f.list(r.Rules, f.format.chain)
+	case *Either:
+		return f.list(r.Rules, f.format.either)
+	case *Repetition:
+		return f.format.repetition(f.expr(r.Rule), r.Times, r.Variadic)
+	case *Optional:
+		return f.format.optional(f.expr(r.Rule))
+	case *SepBy:
+		return f.sepBy(r)
+	case *Bound:
+		body := f.format.special("any")
+		if r.Rule != nil {
+			body = f.expr(r.Rule)
+		}
+		return f.expr(r.Open) + f.format.chain + body + f.format.chain + f.expr(r.Close)
+	case *Wrapper:
+		return f.expr(r.Rule)
+	default:
+		if rule.IsFinite() {
+			return f.finite(rule)
+		}
+		return f.special(rule)
+	}
+}
+
+// sepBy formats the SepBy as a combination of repetitions.
+func (f *grammarFormatter) sepBy(r *SepBy) string {
+	item, sep := f.expr(r.Rule), f.expr(r.Separator)
+	if r.Trailing == SepByTrailingRequire {
+		return f.format.repetition(f.format.group(item+f.format.chain+sep), r.Min, true)
+	}
+
+	tail := f.format.group(sep + f.format.chain + item)
+	body := item
+	if r.Min > 1 {
+		body += f.format.chain + f.format.repetition(tail, r.Min-1, false)
+	}
+	body += f.format.chain + f.format.repetition(tail, 0, true)
+	if r.Trailing == SepByTrailingAllow {
+		body += f.format.chain + f.format.optional(sep)
+	}
+	if r.Min == 0 {
+		return f.format.optional(body)
+	}
+	return body
+}
+
+func (f *grammarFormatter) list(rules Rules, delimiter string) string {
+	exprs := make([]string, len(rules))
+	for k, rule := range rules {
+		exprs[k] = f.expr(rule)
+	}
+	return strings.Join(exprs, delimiter)
+}
+
+// expr formats the rule as expression which
+// could be used inside production body.
+func (f *grammarFormatter) expr(rule Rule) string {
+	rule = Unref(rule)
+	if rule == nil {
+		return f.format.special(nilLabel)
+	}
+	if isRefUnresolved(rule) {
+		return f.format.special(rule.Name())
+	}
+	if !rule.IsFinite() {
+		return f.names[rule]
+	}
+	return f.finite(rule)
+}
+
+// finite formats the finite rule which is inlined into expressions.
+func (f *grammarFormatter) finite(rule Rule) string {
+	switch r := rule.(type) {
+	case *Terminal:
+		return f.format.terminal(r.Value, r.Fold)
+	case *Keywords:
+		keywords := r.Keywords()
+		alternatives := make([]string, len(keywords))
+		for k, keyword := range keywords {
+			alternatives[k] = f.format.terminal([]byte(keyword), r.Fold)
+		}
+		return "( " + strings.Join(alternatives, f.format.either) + " )"
+	case *Regexp:
+		return f.format.regexp(r.Expr)
+	default:
+		return f.special(rule)
+	}
+}
+
+// special formats the rule which has no equivalent in the notation
+// using Treer representation with childs as references.
+func (f *grammarFormatter) special(rule Rule) string {
+	childs := rule.GetChilds()
+	refs := make([]string, 0, len(childs))
+	for _, child := range childs {
+		if r, ok := child.(Rule); ok {
+			refs = append(refs, f.expr(r))
+		}
+	}
+	return f.format.special(rule.Show(strings.Join(refs, treerDelimiter)))
+}
+
+// isRefUnresolved returns true if rule is a Ref without Target,
+// it is formatted as a special sequence with the Ref name.
+func isRefUnresolved(rule Rule) bool {
+	ref, ok := rule.(*Ref)
+	return ok && ref.Target == nil
+}
@@ -0,0 +1,230 @@
+package parse
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFormatGrammar() Rule {
+	number := NewRegexp("number", "^[0-9]+")
+	expression := NewEither("expression")
+	group := NewChain(
+		"group",
+		NewTerminal("(", "("),
+		expression,
+		NewTerminal(")", ")"),
+	)
+	expression.Add(
+		NewChain(
+			"sum",
+			number,
+			NewRepetitionTimesVariadic(
+				"tail",
+				0,
+				NewChain("plus number", NewTerminal("+", "+"), number),
+			),
+		),
+		group,
+		NewRepetitionTimes("pair", 2, NewTerminal("quote", `"`)),
+		newTestRuleFinite("custom"),
+	)
+	return expression
+}
+
+func TestFormatEBNF(t *testing.T) {
+	samples := []struct {
+		rule Rule
+		text string
+	}{
+		{
+			NewTerminal("foo", "foo"),
+			"foo = \"foo\" ;\n",
+		},
+		{
+			NewWrapper("foo bar", NewTerminal("foo", "foo")),
+			"foo_bar = \"foo\" ;\n",
+		},
+		{
+			NewRepetition("foos", NewTerminal("foo", "foo")),
+			"foos = \"foo\" , { \"foo\" } ;\n",
+		},
+		{
+			NewOptional("maybe foo", NewTerminal("foo", "foo")),
+			"maybe_foo = [ \"foo\" ] ;\n",
+		},
+		{
+			NewChain(
+				"statement",
+				NewTerminalFold("select", "select"),
+				NewKeywords("column", []string{"id", "name"}),
+			),
+			"statement = ? case-insensitive \"select\" ? , ( \"id\" | \"name\" ) ;\n",
+		},
+		{
+			NewSepBy("args", NewTerminal("x", "x"), NewTerminal(",", ",")),
+			"args = [ \"x\" , { ( \",\" , \"x\" ) } ] ;\n",
+		},
+		{
+			&SepBy{
+				name:      "args",
+				Rule:      NewTerminal("x", "x"),
+				Separator: NewTerminal(",", ","),
+				Min:       2,
+				Trailing:  SepByTrailingAllow,
+			},
+			"args = \"x\" , 1 * ( \",\" , \"x\" ) , { ( \",\" , \"x\" ) } , [ \",\" ] ;\n",
+		},
+		{
+			&SepBy{
+				name:      "statements",
+				Rule:      NewTerminal("x", "x"),
+				Separator: NewTerminal(";", ";"),
+				Trailing:  SepByTrailingRequire,
+			},
+			"statements = { ( \"x\" , \";\" ) } ;\n",
+		},
+		{
+			newTestFormatGrammar(),
+			strings.Join([]string{
+				`expression = sum | group | pair | ? *parse.testRuleFinite(name: custom)() ? ;`,
+				`sum = /^[0-9]+/ , tail ;`,
+				`tail = { plus_number } ;`,
+				`plus_number = "+" , /^[0-9]+/ ;`,
+				`group = "(" , expression , ")" ;`,
+				`pair = 2 * "\"" ;`,
+			}, "\n") + "\n",
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample)
+			assert.Equal(t, sample.text, FormatEBNF(sample.rule), msg)
+		})
+	}
+}
+
+func TestFormatEBNFLoad(t *testing.T) {
+	grammar := `
+expression = term , { ( "+" | "-" ) , term } ;
+term       = factor { ("*" | "/") factor } ;
+factor     = number | "(" expression ")" ;
+number     = /[0-9]+/ ;
+`
+	rules, err := LoadEBNF(strings.NewReader(grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := FormatEBNF(rules["expression"])
+	loaded, err := LoadEBNF(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, text, FormatEBNF(loaded["expression"]))
+
+	for _, input := range []string{"1", "1+2*(3-4)/5"} {
+		_, err = Parse(loaded["expression"], []byte(input))
+		assert.Nil(t, err, input)
+	}
+}
+
+func TestFormatEBNFLoadSpecial(t *testing.T) {
+	x := NewTerminal("x", "x")
+	rule := NewChain(
+		"everything",
+		NewTerminalFold("select", "select?"),
+		NewKeywords("column", []string{"id", "name"}),
+		NewKeywordsFold("order", []string{"asc", "desc"}),
+		NewRegexp("number", "^[0-9]+"),
+		NewEither("either", x, NewTerminal("y", "y")),
+		NewRepetition("one or more", x),
+		NewRepetitionTimes("pair", 2, x),
+		NewRepetitionTimesVariadic("three or more", 3, x),
+		NewOptional("maybe", x),
+		NewSepBy("args", x, NewTerminal(",", ",")),
+		&SepBy{name: "items", Rule: x, Separator: NewTerminal(",", ","), Min: 2, Trailing: SepByTrailingAllow},
+		&SepBy{name: "statements", Rule: x, Separator: NewTerminal(";", ";"), Trailing: SepByTrailingRequire},
+		NewBound("parens", NewTerminal("(", "("), x, NewTerminal(")", ")")),
+		NewBoundNested("nested", NewTerminal("{", "{"), NewTerminal("}", "}")),
+		NewWrapper("empty", nil),
+		NewNot("not x", x),
+		newTestRuleFinite("custom"),
+	)
+
+	text := FormatEBNF(rule)
+	loaded, err := LoadEBNF(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// NOTE: some finite rules are loaded as non-finite (Keywords as Either),
+	// so formatted loaded grammar is a fixed point
+	reformatted := FormatEBNF(loaded["everything"])
+	reloaded, err := LoadEBNF(strings.NewReader(reformatted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, reformatted, FormatEBNF(reloaded["everything"]))
+
+	selectRule := loaded["everything"].(*Chain).Rules[0]
+	assert.Equal(t, NewTerminalFold("select?", "select?"), selectRule)
+	tree, err := Parse(selectRule, []byte("SeLeCt?"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("SeLeCt?"), tree.Data)
+
+	custom := loaded["everything"].(*Chain).Rules[16]
+	assert.Equal(t, NewRef("*parse.testRuleFinite(name: custom)()"), custom)
+	_, err = Parse(custom, []byte("x"))
+	assert.Equal(t, NewErrRuleUndefined("*parse.testRuleFinite(name: custom)()"), err)
+
+	_, err = LoadEBNF(strings.NewReader("foo = ? unterminated ;"))
+	assert.IsType(t, &ErrGrammar{}, err)
+}
+
+func TestFormatABNF(t *testing.T) {
+	samples := []struct {
+		rule Rule
+		text string
+	}{
+		{
+			NewRepetition("foos", NewTerminal("foo", "foo")),
+			"foos = 1*%s\"foo\"\n",
+		},
+		{
+			NewOptional("maybe foo", NewTerminal("foo", "foo")),
+			"maybe-foo = [%s\"foo\"]\n",
+		},
+		{
+			NewChain(
+				"statement",
+				NewTerminalFold("select", "select"),
+				NewKeywordsFold("column", []string{"id", "name"}),
+			),
+			"statement = \"select\" ( \"id\" / \"name\" )\n",
+		},
+		{
+			NewSepBy1("args", NewTerminal("x", "x"), NewTerminal(",", ",")),
+			"args = %s\"x\" *(%s\",\" %s\"x\")\n",
+		},
+		{
+			newTestFormatGrammar(),
+			strings.Join([]string{
+				`expression = sum / group / pair / <*parse.testRuleFinite(name: custom)()>`,
+				`sum = <regexp ^[0-9]+> tail`,
+				`tail = *plus-number`,
+				`plus-number = %s"+" <regexp ^[0-9]+>`,
+				`group = %s"(" expression %s")"`,
+				`pair = 2%x22`,
+			}, "\n") + "\n",
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample)
+			assert.Equal(t, sample.text, FormatABNF(sample.rule), msg)
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package parse
+
+import (
+	"errors"
+)
+
+// Grammar is a registry of named rules (productions) with a start rule.
+// Rules could reference each other with Ref before
+// they are defined, references are bound with Resolve.
+// Start is a name of the start rule, first defined rule
+// is used if it is empty.
+//
+//	g := NewGrammar()
+//	g.Define("expression", NewEither("expression", g.Ref("number"), g.Ref("group")))
+//	g.Define("group", NewChain("group", NewTerminal("(", "("), g.Ref("expression"), NewTerminal(")", ")")))
+//	g.Define("number", NewRegexp("number", "^[0-9]+"))
+//	err := g.Validate()
+type Grammar struct {
+	Start string
+
+	rules map[string]Rule
+	names []string
+	refs  []*Ref
+	errs  []error
+}
+
+// Define registers the rule with specified name and returns it.
+// Defining the same name twice is reported by Resolve.
+func (g *Grammar) Define(name string, rule Rule) Rule {
+	if _, ok := g.rules[name]; ok {
+		g.errs = append(g.errs, NewErrRuleDuplicate(name))
+		return rule
+	}
+	g.rules[name] = rule
+	g.names = append(g.names, name)
+	return rule
+}
+
+// Ref constructs new *Ref to the rule with specified name
+// which will be bound to the rule by Resolve.
+func (g *Grammar) Ref(name string) *Ref {
+	ref := NewRef(name)
+	g.refs = append(g.refs, ref)
+	return ref
+}
+
+// Rule returns a rule defined with specified name
+// or nil if there is no such rule.
+func (g *Grammar) Rule(name string) Rule {
+	return g.rules[name]
+}
+
+// StartRule returns the start rule or nil if it is not defined.
+func (g *Grammar) StartRule() Rule {
+	if g.Start == "" {
+		if len(g.names) == 0 {
+			return nil
+		}
+		return g.rules[g.names[0]]
+	}
+	return g.rules[g.Start]
+}
+
+// Names returns names of defined rules in order of definition.
+func (g *Grammar) Names() []string {
+	return g.names
+}
+
+// Resolve binds every Ref created with Grammar.Ref or
+// reachable from defined rules to the rule with the same name.
+// It reports all duplicate definitions and undefined references at once.
+func (g *Grammar) Resolve() error {
+	var (
+		errs      = append([]error{}, g.errs...)
+		refs      = append([]*Ref{}, g.refs...)
+		seen      = map[Rule]bool{}
+		undefined = map[string]bool{}
+		walk      func(rule Rule)
+	)
+	walk = func(rule Rule) {
+		if rule == nil || seen[rule] {
+			return
+		}
+		seen[rule] = true
+		if ref, ok := rule.(*Ref); ok && ref.Target == nil {
+			refs = append(refs, ref)
+		}
+		for _, child := range rule.GetChilds() {
+			if r, ok := child.(Rule); ok {
+				walk(r)
+			}
+		}
+	}
+	for _, name := range g.names {
+		walk(g.rules[name])
+	}
+
+	for _, ref := range refs {
+		rule, ok := g.rules[ref.name]
+		if !ok {
+			if !undefined[ref.name] {
+				undefined[ref.name] = true
+				errs = append(errs, NewErrRuleUndefined(ref.name))
+			}
+			continue
+		}
+		ref.Target = rule
+	}
+	return errors.Join(errs...)
+}
+
+// Validate resolves references and checks the grammar for errors
+// which otherwise would be found only when some input reaches the broken rule:
+//
+//   - duplicate definitions & undefined references (see Resolve),
+//   - undefined start rule,
+//   - rules which are not reachable from the start rule,
+//   - nil rules inside other rules,
+//   - empty rules, like Chain or Either without rules,
+//   - cycles which never terminate, like `a = "x" , a`,
+//   - repetitions of rules which could match an empty input (see Analyze).
+//
+// All errors are reported at once.
+func (g *Grammar) Validate() error {
+	errs := []error{}
+	if err := g.Resolve(); err != nil {
+		errs = append(errs, err.(interface{ Unwrap() []error }).Unwrap()...)
+	}
+
+	start := g.StartRule()
+	if start == nil && g.Start != "" {
+		errs = append(errs, NewErrRuleUndefined(g.Start))
+	}
+
+	reachable := map[Rule]bool{}
+	grammarWalk(Rules{start}, func(rule Rule, parent Rule) {
+		reachable[rule] = true
+	})
+	for _, name := range g.names {
+		if start != nil && !reachable[g.rules[name]] {
+			errs = append(errs, NewErrRuleUnreachable(name))
+		}
+	}
+
+	rules := Rules{}
+	roots := make(Rules, len(g.names))
+	for k, name := range g.names {
+		roots[k] = g.rules[name]
+	}
+	grammarWalk(roots, func(rule Rule, parent Rule) {
+		if rule == nil {
+			errs = append(errs, NewErrRuleNil(parent))
+			return
+		}
+		rules = append(rules, rule)
+		if grammarRuleEmpty(rule) {
+			errs = append(errs, NewErrEmptyRule(rule, parent))
+		}
+	})
+
+	productive := grammarProductive(rules)
+	for _, rule := range rules {
+		if !productive[rule] && grammarCyclic(rule, productive) {
+			errs = append(errs, NewErrRuleCycle(rule))
+		}
+	}
+
+	if start != nil {
+		if err := Analyze(start).Errors(); err != nil {
+			errs = append(errs, err.(interface{ Unwrap() []error }).Unwrap()...)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+//
+
+// grammarWalk visits every rule reachable from the roots once
+// (nil rules are visited every time they are found) with its parent.
+func grammarWalk(roots Rules, fn func(rule Rule, parent Rule)) {
+	var (
+		seen = map[Rule]bool{}
+		walk func(rule Rule, parent Rule)
+	)
+	walk = func(rule Rule, parent Rule) {
+		if rule != nil && seen[rule] {
+			return
+		}
+		fn(rule, parent)
+		if rule == nil {
+			return
+		}
+		seen[rule] = true
+		for _, child := range rule.GetChilds() {
+			r, _ := child.(Rule)
+			walk(r, rule)
+		}
+	}
+	for _, root := range roots {
+		if root != nil {
+			walk(root, nil)
+		}
+	}
+}
+
+// grammarRuleEmpty returns true if rule has no content to match.
+func grammarRuleEmpty(rule Rule) bool {
+	switch r := rule.(type) {
+	case *Chain:
+		return len(r.Rules) == 0
+	case *Either:
+		return len(r.Rules) == 0
+	case *Terminal:
+		return len(r.Value) == 0
+	case *Keywords:
+		return len(r.Keywords()) == 0
+	case *RuneClass:
+		return len(r.Tables) == 0
+	case *StringLiteral:
+		return len(r.Quotes) == 0
+	case *Regexp:
+		return r.Regexp == nil
+	}
+	return false
+}
+
+// grammarProductive returns a set of rules which could match some input
+// in a finite number of steps, it is a fixed point of the productivity
+// rules, so rules which are part of infinite cycles are not in the set.
+func grammarProductive(rules Rules) map[Rule]bool {
+	var (
+		productive = map[Rule]bool{}
+		is         = func(rule Rule) bool { return rule != nil && productive[rule] }
+		changed    = true
+	)
+	for changed {
+		changed = false
+		for _, rule := range rules {
+			if productive[rule] {
+				continue
+			}
+
+			var ok bool
+			switch r := rule.(type) {
+			case *Chain:
+				ok = true
+				for _, sub := range r.Rules {
+					ok = ok && is(sub)
+				}
+			case *Either:
+				for _, sub := range r.Rules {
+					ok = ok || is(sub)
+				}
+			case *Repetition:
+				ok = (r.Variadic && r.Times == 0) || is(r.Rule)
+			case *SepBy:
+				ok = r.Min == 0 || (is(r.Rule) && (r.Min == 1 || is(r.Separator)))
+			case *Bound:
+				ok = is(r.Open) && is(r.Close) && (r.Rule == nil || is(r.Rule))
+			case *Expression:
+				ok = is(r.Operand)
+			case *Optional, *Not:
+				ok = true
+			case *And:
+				ok = is(r.Rule)
+			case *Wrapper:
+				ok = is(r.Rule)
+			case *Ref:
+				ok = is(r.Target)
+			default:
+				if rule.IsFinite() {
+					ok = true
+					break
+				}
+				for _, child := range rule.GetChilds() {
+					sub, _ := child.(Rule)
+					ok = ok || is(sub)
+				}
+			}
+			if ok {
+				productive[rule] = true
+				changed = true
+			}
+		}
+	}
+	return productive
+}
+
+// grammarCyclic returns true if the non-productive rule
+// could reach itself through non-productive rules.
+func grammarCyclic(rule Rule, productive map[Rule]bool) bool {
+	var (
+		seen  = map[Rule]bool{}
+		found = false
+		walk  func(r Rule)
+	)
+	walk = func(r Rule) {
+		for _, child := range r.GetChilds() {
+			sub, _ := child.(Rule)
+			if found || sub == nil || productive[sub] {
+				continue
+			}
+			if sub == rule {
+				found = true
+				return
+			}
+			if seen[sub] {
+				continue
+			}
+			seen[sub] = true
+			walk(sub)
+		}
+	}
+	walk(rule)
+	return found
+}
+
+//
+
+// NewGrammar constructs new empty *Grammar.
+func NewGrammar() *Grammar {
+	return &Grammar{
+		rules: map[string]Rule{},
+	}
+}
@@ -0,0 +1,151 @@
+package parse
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestGrammar() *Grammar {
+	g := NewGrammar()
+	g.Define(
+		"expression",
+		NewChain(
+			"expression",
+			g.Ref("term"),
+			NewRepetitionTimesVariadic(
+				"tail",
+				0,
+				NewChain("plus term", NewTerminal("+", "+"), g.Ref("term")),
+			),
+		),
+	)
+	g.Define(
+		"term",
+		NewEither(
+			"term",
+			NewRegexp("number", "^[0-9]+"),
+			NewChain("group", NewTerminal("(", "("), NewRef("expression"), NewTerminal(")", ")")),
+		),
+	)
+	return g
+}
+
+func TestGrammar(t *testing.T) {
+	g := newTestGrammar()
+	if err := g.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"expression", "term"}, g.Names())
+	assert.Nil(t, g.Rule("undefined"))
+
+	for _, input := range []string{"1", "1+2", "(1+(2+3))+4"} {
+		tree, err := Parse(g.Rule("expression"), []byte(input))
+		assert.Nil(t, err, input)
+		if err == nil {
+			assert.Equal(t, []byte(input), tree.Data, input)
+		}
+	}
+
+	assert.Equal(
+		t,
+		strings.Join([]string{
+			`expression = term , tail ;`,
+			`term = /^[0-9]+/ | group ;`,
+			`group = "(" , expression , ")" ;`,
+			`tail = { plus_term } ;`,
+			`plus_term = "+" , term ;`,
+		}, "\n")+"\n",
+		FormatEBNF(g.Rule("expression")),
+	)
+}
+
+func TestGrammarResolveErrors(t *testing.T) {
+	g := newTestGrammar()
+	g.Define("term", NewTerminal("term", "x"))
+	g.Define("statement", NewChain("statement", g.Ref("expression"), g.Ref("semicolon")))
+	g.Define("block", NewRepetition("block", NewRef("semicolon")))
+
+	err := g.Resolve()
+	assert.NotNil(t, err)
+
+	var (
+		undefined *ErrRuleUndefined
+		duplicate *ErrRuleDuplicate
+	)
+	assert.True(t, errors.As(err, &undefined))
+	assert.Equal(t, "semicolon", undefined.Name)
+	assert.True(t, errors.As(err, &duplicate))
+	assert.Equal(t, "term", duplicate.Name)
+	assert.Equal(
+		t,
+		strings.Join([]string{
+			`Rule "term" is defined more than once`,
+			`Rule "semicolon" is referenced but not defined`,
+		}, "\n"),
+		err.Error(),
+	)
+}
+
+func TestGrammarValidate(t *testing.T) {
+	valid := newTestGrammar()
+	assert.Nil(t, valid.Validate())
+	assert.Equal(t, valid.Rule("expression"), valid.StartRule())
+
+	empty := NewChain("empty")
+	loop := NewChain("loop", NewTerminal("x", "x"))
+	loop.Add(loop)
+	ping := NewEither("ping")
+	pong := NewChain("pong", NewTerminal("y", "y"), ping)
+	ping.Add(pong)
+
+	g := newTestGrammar()
+	g.Start = "program"
+	g.Define("program", NewChain("program", g.Ref("expression"), g.Ref("semicolon")))
+	g.Define("orphan", NewTerminal("orphan", "orphan"))
+	g.Define("broken", NewChain("broken", NewTerminal("z", "z"), nil, empty))
+	g.Define("loop", loop)
+	g.Define("ping", ping)
+
+	err := g.Validate()
+	assert.NotNil(t, err)
+	assert.Equal(
+		t,
+		strings.Join([]string{
+			`Rule "semicolon" is referenced but not defined`,
+			`Rule "orphan" is not reachable from the start rule`,
+			`Rule "broken" is not reachable from the start rule`,
+			`Rule "loop" is not reachable from the start rule`,
+			`Rule "ping" is not reachable from the start rule`,
+			`Nil rule inside '*parse.Chain' = 'broken' rule`,
+			NewErrEmptyRule(empty, g.Rule("broken")).Error(),
+			`Rule '*parse.Chain' = 'loop' is a part of the cycle which never terminates`,
+			`Rule '*parse.Either' = 'ping' is a part of the cycle which never terminates`,
+			`Rule '*parse.Chain' = 'pong' is a part of the cycle which never terminates`,
+		}, "\n"),
+		err.Error(),
+	)
+
+	var cycle *ErrRuleCycle
+	assert.True(t, errors.As(err, &cycle))
+	assert.Equal(t, loop, cycle.Rule)
+
+	undefined := NewGrammar()
+	undefined.Start = "main"
+	undefined.Define("other", NewTerminal("x", "x"))
+	assert.Equal(
+		t,
+		`Rule "main" is referenced but not defined`,
+		undefined.Validate().Error(),
+	)
+
+	spaces := NewGrammar()
+	spaces.Define("spaces", NewRepetitionTimesVariadic("spaces", 0, NewRegexp("space", "^ *")))
+	assert.Equal(
+		t,
+		`Rule '*parse.Repetition' = 'spaces' repeats a rule which could match an empty input`,
+		spaces.Validate().Error(),
+	)
+}
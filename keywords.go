@@ -0,0 +1,213 @@
+package parse
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+var _ Rule = new(Keywords)
+
+// Keywords is a Rule which matches the longest literal
+// from the set of keywords. It uses a prefix tree, so
+// matching does not depend on the number of keywords
+// like it would with Either of Terminal.
+// If Fold is set then keywords are matched using unicode
+// simple case-folding, it should not be changed after
+// keywords were added.
+//
+// Tree.Value contains the matched keyword as it was
+// added to the set, which is useful with Fold.
+type Keywords struct {
+	name       string
+	keywords   []string
+	trie       *keywordsNode
+	Fold       bool
+	Hooks      []RuleParseHook
+	EnterHooks []RuleEnterHook
+}
+
+// keywordsNode is a node of the keywords prefix tree.
+type keywordsNode struct {
+	childs  map[rune]*keywordsNode
+	keyword string
+	end     bool
+}
+
+// Name indicates the name which was given to the rule
+// on creation. Name could be not unique.
+func (r *Keywords) Name() string {
+	return r.name
+}
+
+// Show this node as a string.
+// You should provide childs as string
+// to this function, it does not care
+// about nesting in a tree, it only shows
+// string representation of itself.
+func (r *Keywords) Show(childs string) string {
+	return RuleShow(
+		r,
+		r.GetParameters().String(),
+		childs,
+	)
+}
+
+// String returns rule as a string,
+// resolving recursion with `<circular>` placeholder.
+func (r *Keywords) String() string {
+	return TreerString(r)
+}
+
+// GetChilds returns a slice of Rule which is
+// children for current Rule.
+func (r *Keywords) GetChilds() Treers {
+	return nil
+}
+
+//
+
+// GetParameters returns a KV rule parameters.
+func (r *Keywords) GetParameters() RuleParameters {
+	return RuleParameters{
+		"name":     r.name,
+		"keywords": strings.Join(r.keywords, " "),
+		"fold":     r.Fold,
+	}
+}
+
+// IsFinite returns true if this rule is
+// not a wrapper for other rules.
+func (r *Keywords) IsFinite() bool {
+	return true
+}
+
+// Parse consumes some bytes from input & emits a Tree
+// using settings defined during creation of the concrete Rule type.
+// May return an error if something goes wrong, should provide some
+// location information to the user which points to position in input.
+func (r *Keywords) Parse(ctx *Context, input []byte) (*Tree, error) {
+	if err := runEnterHooks(ctx, r, input, r.EnterHooks); err != nil {
+		return nil, err
+	}
+
+	if len(r.keywords) == 0 {
+		return nil, NewErrEmptyRule(r, ctx.Rule)
+	}
+
+	var (
+		node    = r.trie
+		n       int
+		length  int
+		keyword string
+		found   bool
+	)
+	for node != nil && n < len(input) {
+		c, size := utf8.DecodeRune(input[n:])
+		node = node.childs[r.key(c)]
+		n += size
+		if node != nil && node.end {
+			length = n
+			keyword = node.keyword
+			found = true
+		}
+	}
+	if !found {
+		if node != nil {
+			return nil, NewErrUnexpectedEOF(r, ctx.Location)
+		}
+		return nil, NewErrUnexpectedToken(r, ctx.Location, ShowInput(input))
+	}
+
+	line, col := ctx.Parser.Locate(ctx.Location.Position)
+	tree := &Tree{
+		Rule: r,
+		Location: &Location{
+			Path:     ctx.Location.Path,
+			Position: ctx.Location.Position,
+			Line:     line,
+			Column:   col,
+		},
+		Region: &Region{
+			Start: ctx.Location.Position,
+			End:   ctx.Location.Position + length,
+		},
+		Depth: ctx.Depth,
+		Data:  input[:length],
+		Value: keyword,
+	}
+	if err := runHooks(ctx, r, tree, r.Hooks); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// key returns a prefix tree key for rune c.
+func (r *Keywords) key(c rune) rune {
+	if r.Fold {
+		return FoldRune(c)
+	}
+	return c
+}
+
+//
+
+// Keywords returns a list of keywords in order they were added.
+func (r *Keywords) Keywords() []string {
+	return r.keywords
+}
+
+// Add appends keywords into the set.
+// Empty and duplicate keywords are ignored.
+func (r *Keywords) Add(keywords ...string) {
+	if r.trie == nil {
+		r.trie = &keywordsNode{}
+	}
+	for _, keyword := range keywords {
+		if keyword == "" {
+			continue
+		}
+		node := r.trie
+		for _, c := range keyword {
+			key := r.key(c)
+			next, ok := node.childs[key]
+			if !ok {
+				if node.childs == nil {
+					node.childs = map[rune]*keywordsNode{}
+				}
+				next = &keywordsNode{}
+				node.childs[key] = next
+			}
+			node = next
+		}
+		if node.end {
+			continue
+		}
+		node.end = true
+		node.keyword = keyword
+		r.keywords = append(r.keywords, keyword)
+	}
+}
+
+//
+
+// NewKeywords constructs new *Keywords.
+func NewKeywords(name string, keywords []string, hooks ...RuleParseHook) *Keywords {
+	r := &Keywords{
+		name:  name,
+		Hooks: hooks,
+	}
+	r.Add(keywords...)
+	return r
+}
+
+// NewKeywordsFold constructs new *Keywords which
+// matches keywords using unicode case-folding.
+func NewKeywordsFold(name string, keywords []string, hooks ...RuleParseHook) *Keywords {
+	r := &Keywords{
+		name:  name,
+		Fold:  true,
+		Hooks: hooks,
+	}
+	r.Add(keywords...)
+	return r
+}
@@ -0,0 +1,122 @@
+package parse
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeywordsShow(t *testing.T) {
+	samples := []struct {
+		rule Rule
+		show string
+	}{
+		{
+			NewKeywords("keywords", []string{"in", "insert", "", "in"}),
+			"*parse.Keywords(fold: false, keywords: in insert, name: keywords)(none)",
+		},
+		{
+			NewKeywordsFold("keywords", []string{"select", "SELECT"}),
+			"*parse.Keywords(fold: true, keywords: select, name: keywords)(none)",
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample)
+			assert.EqualValues(t, sample.show, sample.rule.Show("none"), msg)
+			assert.Nil(t, sample.rule.GetChilds(), msg)
+			assert.True(t, sample.rule.IsFinite(), msg)
+		})
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	keywords := NewKeywords("keywords", []string{"in", "insert", "into", "select"})
+	folded := NewKeywordsFold("keywords", []string{"in", "INSERT", "Straße", "ключ"})
+	statement := NewChain(
+		"statement",
+		folded,
+		NewTerminal("space", " "),
+		NewRegexp("name", "^[a-z]+"),
+	)
+
+	samples := []struct {
+		text  string
+		rule  Rule
+		data  []string
+		value string
+		err   error
+	}{
+		{"in", keywords, nil, "in", nil},
+		{"insert", keywords, nil, "insert", nil},
+		{"into", keywords, nil, "into", nil},
+		{"InSeRt", folded, nil, "INSERT", nil},
+		{"STRASSE", folded, nil, "", NewErrUnexpectedToken(folded, &Location{Path: DefaultParserPath}, []byte("STRASSE"))},
+		{"STRAßE", folded, nil, "Straße", nil},
+		{"КЛЮЧ", folded, nil, "ключ", nil},
+		{"insert foo", statement, []string{"insert", " ", "foo"}, "", nil},
+		{
+			"ins",
+			keywords,
+			nil,
+			"",
+			NewErrUnexpectedToken(
+				keywords,
+				&Location{Path: DefaultParserPath, Position: 2, Column: 2},
+				[]byte("s"),
+				NewErrUnmatchedInput([]byte("s")),
+			),
+		},
+		{
+			"sel",
+			keywords,
+			nil,
+			"",
+			NewErrUnexpectedEOF(keywords, &Location{Path: DefaultParserPath}),
+		},
+		{
+			"update",
+			keywords,
+			nil,
+			"",
+			NewErrUnexpectedToken(
+				keywords,
+				&Location{Path: DefaultParserPath},
+				[]byte("update"),
+			),
+		},
+		{
+			"SELECT",
+			keywords,
+			nil,
+			"",
+			NewErrUnexpectedToken(
+				keywords,
+				&Location{Path: DefaultParserPath},
+				[]byte("SELECT"),
+			),
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample.text)
+			tree, err := Parse(sample.rule, []byte(sample.text))
+			assert.EqualValues(t, sample.err, err, msg)
+			if sample.err != nil {
+				return
+			}
+			assert.Equal(t, []byte(sample.text), tree.Data, msg)
+			if sample.data != nil {
+				data := make([]string, len(tree.Childs))
+				for n, child := range tree.Childs {
+					data[n] = string(child.Data)
+				}
+				assert.Equal(t, sample.data, data, msg)
+				return
+			}
+			assert.Equal(t, sample.value, tree.Value, msg)
+		})
+	}
+}
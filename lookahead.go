@@ -0,0 +1,230 @@
+package parse
+
+import (
+	"fmt"
+)
+
+var (
+	_ Rule = new(Not)
+	_ Rule = new(And)
+)
+
+var ErrLookaheadMatched = fmt.Errorf("negative lookahead matched")
+
+// Not is a negative lookahead predicate (PEG `!e`).
+// It runs inner Rule at the current position and succeeds
+// only if inner Rule fails. It never consumes input and produces
+// no data, on success it returns ErrSkipRule, so it is not
+// included into the Chain childs.
+type Not struct {
+	name string
+	Rule Rule
+}
+
+// Name indicates the name which was given to the rule
+// on creation. Name could be not unique.
+func (r *Not) Name() string {
+	return r.name
+}
+
+// Show this node as a string.
+// You should provide childs as string
+// to this function, it does not care
+// about nesting in a tree, it only shows
+// string representation of itself.
+func (r *Not) Show(childs string) string {
+	return RuleShow(
+		r,
+		r.GetParameters().String(),
+		childs,
+	)
+}
+
+// String returns rule as a string,
+// resolving recursion with `<circular>` placeholder.
+func (r *Not) String() string {
+	return TreerString(r)
+}
+
+// GetChilds returns a slice of Rule which is
+// children for current Rule.
+func (r *Not) GetChilds() Treers {
+	return Treers{r.Rule}
+}
+
+//
+
+// GetParameters returns a KV rule parameters.
+func (r *Not) GetParameters() RuleParameters {
+	return RuleParameters{
+		"name": r.name,
+	}
+}
+
+// IsFinite returns true if this rule is
+// not a wrapper for other rules.
+func (r *Not) IsFinite() bool {
+	return false
+}
+
+// Parse consumes some bytes from input & emits a Tree
+// using settings defined during creation of the concrete Rule type.
+// May return an error if something goes wrong, should provide some
+// location information to the user which points to position in input.
+func (r *Not) Parse(ctx *Context, input []byte) (*Tree, error) {
+	err := lookahead(r, r.Rule, ctx, input)
+	if err == nil {
+		return nil, NewErrUnexpectedToken(
+			r,
+			ctx.Location,
+			ShowInput(input),
+			ErrLookaheadMatched,
+		)
+	}
+
+	switch err.(type) {
+	case *ErrUnexpectedToken, *ErrUnexpectedEOF:
+		return nil, ErrSkipRule
+	default:
+		return nil, err
+	}
+}
+
+//
+
+// NewNot constructs new *Not.
+func NewNot(name string, r Rule) *Not {
+	return &Not{
+		name: name,
+		Rule: r,
+	}
+}
+
+//
+
+// And is a positive lookahead predicate (PEG `&e`).
+// It runs inner Rule at the current position and succeeds
+// only if inner Rule succeeds. It never consumes input and produces
+// no data, on success it returns ErrSkipRule, so it is not
+// included into the Chain childs.
+type And struct {
+	name string
+	Rule Rule
+}
+
+// Name indicates the name which was given to the rule
+// on creation. Name could be not unique.
+func (r *And) Name() string {
+	return r.name
+}
+
+// Show this node as a string.
+// You should provide childs as string
+// to this function, it does not care
+// about nesting in a tree, it only shows
+// string representation of itself.
+func (r *And) Show(childs string) string {
+	return RuleShow(
+		r,
+		r.GetParameters().String(),
+		childs,
+	)
+}
+
+// String returns rule as a string,
+// resolving recursion with `<circular>` placeholder.
+func (r *And) String() string {
+	return TreerString(r)
+}
+
+// GetChilds returns a slice of Rule which is
+// children for current Rule.
+func (r *And) GetChilds() Treers {
+	return Treers{r.Rule}
+}
+
+//
+
+// GetParameters returns a KV rule parameters.
+func (r *And) GetParameters() RuleParameters {
+	return RuleParameters{
+		"name": r.name,
+	}
+}
+
+// IsFinite returns true if this rule is
+// not a wrapper for other rules.
+func (r *And) IsFinite() bool {
+	return false
+}
+
+// Parse consumes some bytes from input & emits a Tree
+// using settings defined during creation of the concrete Rule type.
+// May return an error if something goes wrong, should provide some
+// location information to the user which points to position in input.
+func (r *And) Parse(ctx *Context, input []byte) (*Tree, error) {
+	err := lookahead(r, r.Rule, ctx, input)
+	if err == nil {
+		return nil, ErrSkipRule
+	}
+
+	switch err.(type) {
+	case *ErrUnexpectedToken, *ErrUnexpectedEOF:
+		return nil, NewErrUnexpectedToken(
+			r,
+			ctx.Location,
+			ShowInput(input),
+			err,
+		)
+	default:
+		return nil, err
+	}
+}
+
+//
+
+// NewAnd constructs new *And.
+func NewAnd(name string, r Rule) *And {
+	return &And{
+		name: name,
+		Rule: r,
+	}
+}
+
+//
+
+// lookahead applies inner Rule of the predicate at the current position
+// returning nil if it matched (even if it was skipped).
+func lookahead(predicate Rule, inner Rule, ctx *Context, input []byte) error {
+	if inner == nil {
+		return NewErrEmptyRule(predicate, ctx.Rule)
+	}
+
+	nextDepth := ctx.Depth + 1
+	if nextDepth > ctx.Parser.MaxDepth {
+		return NewErrNestingTooDeep(ctx.Location, nextDepth)
+	}
+
+	failure := ctx.Parser.failure // NOTE: predicates do not expect anything
+	defer func() { ctx.Parser.failure = failure }()
+	defer ctx.snapshot().restore() // NOTE: predicates do not change state
+
+	ctx.Parser.noRecover++ // NOTE: predicates do not recover
+	defer func() { ctx.Parser.noRecover-- }()
+
+	_, err := ctx.Parser.ParseRule(
+		inner,
+		&Context{
+			Rule:     predicate,
+			Parser:   ctx.Parser,
+			Location: ctx.Location,
+			Depth:    nextDepth,
+			State:    ctx.State,
+		},
+		input,
+	)
+	if err == ErrSkipRule {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+package parse
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookaheadShow(t *testing.T) {
+	samples := []struct {
+		rule   Rule
+		childs string
+		show   string
+	}{
+		{
+			NewNot("not foo", NewTerminal("foo", "foo")),
+			"none",
+			"*parse.Not(name: not foo)(none)",
+		},
+		{
+			NewAnd("and foo", NewTerminal("foo", "foo")),
+			"none",
+			"*parse.And(name: and foo)(none)",
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample)
+			assert.EqualValues(t, sample.show, sample.rule.Show(sample.childs), msg)
+			assert.EqualValues(t, Treers{NewTerminal("foo", "foo")}, sample.rule.GetChilds(), msg)
+			assert.False(t, sample.rule.IsFinite(), msg)
+		})
+	}
+}
+
+func TestLookahead(t *testing.T) {
+	identifier := NewRegexp("identifier", "^[a-z_]+")
+	keyword := NewChain(
+		"if",
+		NewTerminal("if", "if"),
+		NewNot("not identifier", identifier),
+	)
+	comment := NewChain(
+		"comment",
+		NewTerminal("open", "/*"),
+		NewRepetitionTimesVariadic(
+			"body",
+			0,
+			NewChain(
+				"body character",
+				NewNot("not close", NewTerminal("close", "*/")),
+				NewRegexp("any", "^(?s:.)"),
+			),
+		),
+		NewTerminal("close", "*/"),
+	)
+	followed := NewChain(
+		"foo followed by bar",
+		NewTerminal("foo", "foo"),
+		NewAnd("and bar", NewTerminal("bar", "bar")),
+		NewRegexp("rest", "^[a-z]+"),
+	)
+
+	samples := []struct {
+		text   string
+		rule   Rule
+		data   []string
+		hasErr bool
+	}{
+		{"if", keyword, []string{"if"}, false},
+		{"iffy", keyword, nil, true},
+		{"/**/", comment, []string{"/*", "*/"}, false},
+		{"/* a * b */", comment, []string{"/*", " a * b ", "*/"}, false},
+		{"/* a */ b */", comment, nil, true},
+		{"foobar", followed, []string{"foo", "bar"}, false},
+		{"foobaz", followed, nil, true},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample.text)
+			tree, err := Parse(sample.rule, []byte(sample.text))
+			if sample.hasErr {
+				assert.NotNil(t, err, msg)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := make([]string, len(tree.Childs))
+			for n, child := range tree.Childs {
+				data[n] = string(child.Data)
+			}
+			assert.Equal(t, sample.data, data, msg)
+			assert.Equal(t, []byte(sample.text), tree.Data, msg)
+		})
+	}
+
+	_, err := Parse(NewNot("empty", nil), []byte("foo"))
+	assert.IsType(t, &ErrEmptyRule{}, err)
+}
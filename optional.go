@@ -0,0 +1,161 @@
+package parse
+
+var _ Rule = new(Optional)
+
+// Optional is a Rule which may be absent in the input.
+// Unlike zero-times Repetition it always yields a Tree,
+// so childs of the parent Chain keep stable indexes.
+// When inner Rule is not matched resulting Tree has
+// empty Region & Data, no childs and Tree.Absent set.
+// Tree.Value of the inner Rule is passed to the Optional Tree.
+type Optional struct {
+	name       string
+	Rule       Rule
+	Hooks      []RuleParseHook
+	EnterHooks []RuleEnterHook
+}
+
+// Name indicates the name which was given to the rule
+// on creation. Name could be not unique.
+func (r *Optional) Name() string {
+	return r.name
+}
+
+// Show this node as a string.
+// You should provide childs as string
+// to this function, it does not care
+// about nesting in a tree, it only shows
+// string representation of itself.
+func (r *Optional) Show(childs string) string {
+	return RuleShow(
+		r,
+		r.GetParameters().String(),
+		childs,
+	)
+}
+
+// String returns rule as a string,
+// resolving recursion with `<circular>` placeholder.
+func (r *Optional) String() string {
+	return TreerString(r)
+}
+
+// GetChilds returns a slice of Rule which is
+// children for current Rule.
+func (r *Optional) GetChilds() Treers {
+	return Treers{r.Rule}
+}
+
+//
+
+// GetParameters returns a KV rule parameters.
+func (r *Optional) GetParameters() RuleParameters {
+	return RuleParameters{
+		"name": r.name,
+	}
+}
+
+// IsFinite returns true if this rule is
+// not a wrapper for other rules.
+func (r *Optional) IsFinite() bool {
+	return false
+}
+
+// Parse consumes some bytes from input & emits a Tree
+// using settings defined during creation of the concrete Rule type.
+// May return an error if something goes wrong, should provide some
+// location information to the user which points to position in input.
+func (r *Optional) Parse(ctx *Context, input []byte) (*Tree, error) {
+	if err := runEnterHooks(ctx, r, input, r.EnterHooks); err != nil {
+		return nil, err
+	}
+
+	if r.Rule == nil {
+		return nil, NewErrEmptyRule(r, ctx.Rule)
+	}
+
+	nextDepth := ctx.Depth + 1
+	if nextDepth > ctx.Parser.MaxDepth {
+		return nil, NewErrNestingTooDeep(
+			ctx.Location,
+			nextDepth,
+		)
+	}
+
+	var (
+		subTree   *Tree
+		err       error
+		line, col = ctx.Parser.Locate(ctx.Location.Position)
+		tree      = &Tree{
+			Rule: r,
+			Location: &Location{
+				Path:     ctx.Location.Path,
+				Position: ctx.Location.Position,
+				Line:     line,
+				Column:   col,
+			},
+			Depth: ctx.Depth,
+		}
+		snapshot = ctx.snapshot()
+	)
+
+	// NOTE: optional rule which does not match is skipped,
+	// recovering it would make valid input fail
+	ctx.Parser.noRecover++
+	subTree, err = ctx.Parser.ParseRule(
+		r.Rule,
+		&Context{
+			Rule:   r,
+			Parser: ctx.Parser,
+			Location: &Location{
+				Path:     ctx.Location.Path,
+				Position: ctx.Location.Position,
+				Line:     line,
+				Column:   col,
+			},
+			Depth: nextDepth,
+			State: ctx.State,
+		},
+		input,
+	)
+	ctx.Parser.noRecover--
+	if err != nil {
+		snapshot.restore()
+		switch err.(type) {
+		case *ErrUnexpectedToken, *ErrUnexpectedEOF:
+		default:
+			if err != ErrSkipRule {
+				return nil, err
+			}
+		}
+
+		tree.Region = &Region{
+			Start: ctx.Location.Position,
+			End:   ctx.Location.Position,
+		}
+		tree.Data = input[:0]
+		tree.Absent = true
+	} else {
+		tree.Region = TreeRegion(subTree)
+		tree.Childs = []*Tree{subTree}
+		tree.Data = input[:tree.Region.End-tree.Region.Start]
+		tree.Value = subTree.Value
+		tree.action = subTree.action
+	}
+
+	if err := runHooks(ctx, r, tree, r.Hooks); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+//
+
+// NewOptional constructs new *Optional.
+func NewOptional(name string, r Rule, hooks ...RuleParseHook) *Optional {
+	return &Optional{
+		name:  name,
+		Rule:  r,
+		Hooks: hooks,
+	}
+}
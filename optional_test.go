@@ -0,0 +1,129 @@
+package parse
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionalShow(t *testing.T) {
+	rule := NewOptional("maybe foo", NewTerminal("foo", "foo"))
+
+	assert.EqualValues(t, "maybe foo", rule.Name())
+	assert.EqualValues(t, "*parse.Optional(name: maybe foo)(none)", rule.Show("none"))
+	assert.EqualValues(t, Treers{NewTerminal("foo", "foo")}, rule.GetChilds())
+	assert.EqualValues(t, RuleParameters{"name": "maybe foo"}, rule.GetParameters())
+	assert.False(t, rule.IsFinite())
+}
+
+func TestOptional(t *testing.T) {
+	newRule := func() Rule {
+		return NewChain(
+			"sign and number",
+			NewOptional("sign", NewTerminal("minus", "-")),
+			NewTerminal("one", "1"),
+		)
+	}
+
+	samples := []struct {
+		text string
+		rule Rule
+		tree *Tree
+		err  error
+	}{
+		{
+			"1",
+			newRule(),
+			&Tree{
+				Rule:     newRule(),
+				Location: &Location{Path: DefaultParserPath},
+				Region:   &Region{Start: 0, End: 1},
+				Childs: []*Tree{
+					{
+						Rule:     NewOptional("sign", NewTerminal("minus", "-")),
+						Location: &Location{Path: DefaultParserPath},
+						Region:   &Region{Start: 0, End: 0},
+						Depth:    1,
+						Data:     []byte{},
+						Absent:   true,
+					},
+					{
+						Rule:     NewTerminal("one", "1"),
+						Location: &Location{Path: DefaultParserPath},
+						Region:   &Region{Start: 0, End: 1},
+						Depth:    1,
+						Data:     []byte("1"),
+					},
+				},
+				Data: []byte("1"),
+			},
+			nil,
+		},
+		{
+			"-1",
+			newRule(),
+			&Tree{
+				Rule:     newRule(),
+				Location: &Location{Path: DefaultParserPath},
+				Region:   &Region{Start: 0, End: 2},
+				Childs: []*Tree{
+					{
+						Rule:     NewOptional("sign", NewTerminal("minus", "-")),
+						Location: &Location{Path: DefaultParserPath},
+						Region:   &Region{Start: 0, End: 1},
+						Depth:    1,
+						Data:     []byte("-"),
+						Childs: []*Tree{
+							{
+								Rule:     NewTerminal("minus", "-"),
+								Location: &Location{Path: DefaultParserPath},
+								Region:   &Region{Start: 0, End: 1},
+								Depth:    2,
+								Data:     []byte("-"),
+							},
+						},
+					},
+					{
+						Rule: NewTerminal("one", "1"),
+						Location: &Location{
+							Path:     DefaultParserPath,
+							Position: 1,
+							Column:   1,
+						},
+						Region: &Region{Start: 1, End: 2},
+						Depth:  1,
+						Data:   []byte("1"),
+					},
+				},
+				Data: []byte("-1"),
+			},
+			nil,
+		},
+		{
+			"+1",
+			newRule(),
+			nil,
+			NewErrUnexpectedToken(
+				NewTerminal("one", "1"),
+				&Location{Path: DefaultParserPath},
+				[]byte("+1"),
+			),
+		},
+		{
+			"",
+			NewOptional("maybe", nil),
+			nil,
+			NewErrEmptyRule(NewOptional("maybe", nil), nil),
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample.rule, sample.text)
+			tree, err := Parse(sample.rule, []byte(sample.text))
+			assert.EqualValues(t, sample.err, err, msg)
+			assert.EqualValues(t, sample.tree, tree, msg)
+		})
+	}
+}
@@ -2,7 +2,8 @@ package parse
 
 import (
 	"fmt"
-	"unicode/utf8"
+	"sync"
+	"sync/atomic"
 )
 
 var (
@@ -19,7 +20,8 @@ var (
 		ParserOptionPath(DefaultParserPath),
 	}
 
-	// DefaultParser is a Parser with default settings.
+	// DefaultParser is a Parser with default settings,
+	// it is safe for concurrent use.
 	DefaultParser = NewParser(DefaultParserOptions...)
 )
 
@@ -29,11 +31,148 @@ func NewErrUnmatchedInput(input []byte) error {
 
 // Parser represents a parser which use Rule's
 // to parse the input.
+// Parser is safe for concurrent use: each Parser.Parse call works
+// with a session (a copy of the Parser available as Context.Parser)
+// which keeps per-input state, so one Parser and one grammar
+// could serve many goroutines at once.
+// Parser options should not be changed while parsing is in progress.
 type Parser struct {
 	MaxDepth  int
 	LineBreak Rule
-	LineIndex []*Region
 	Path      string
+	Memoize   bool
+	Predict   bool
+	Recover   bool
+
+	// Deprecated: line index is a per-call state kept by the session
+	// which is available to rules & hooks as Context.Parser,
+	// Parser.Parse does not set it. Use LocateRegions with
+	// the result of Parser.LineRegions to locate positions of other inputs.
+	LineIndex []*Region
+
+	cache atomic.Pointer[parserCache]
+
+	// per-call state, used by sessions only
+	session   bool
+	lineIndex []*Region
+	analysis  *Analysis
+	failure   parserFailure
+	memo      map[parserMemoKey]*parserMemoEntry
+	memoStats ParserMemoStats
+	calls     []*parserCall
+	active    map[parserMemoKey]*parserCall
+	noRecover int // error recovery is suppressed while > 0
+}
+
+// parserCache is a state shared by sessions of the Parser.
+type parserCache struct {
+	sync.Mutex
+	analyses  map[Rule]*Analysis
+	memoStats ParserMemoStats
+}
+
+// getCache returns the cache shared by sessions of the Parser,
+// it is created on first use, so Parser constructed
+// without NewParser works too.
+func (p *Parser) getCache() *parserCache {
+	if c := p.cache.Load(); c != nil {
+		return c
+	}
+	p.cache.CompareAndSwap(nil, &parserCache{})
+	return p.cache.Load()
+}
+
+// analyze returns cached Analysis of the rule,
+// Analysis is computed on first use.
+func (c *parserCache) analyze(r Rule) *Analysis {
+	c.Lock()
+	defer c.Unlock()
+
+	a := c.analyses[r]
+	if a == nil {
+		a = Analyze(r)
+		if c.analyses == nil {
+			c.analyses = map[Rule]*Analysis{}
+		}
+		c.analyses[r] = a
+	}
+	return a
+}
+
+// storeMemoStats saves memoization statistics of the session
+// to be returned by Parser.MemoStats.
+func (c *parserCache) storeMemoStats(s *Parser) {
+	stats := s.memoStats
+	stats.Size = len(s.memo)
+
+	c.Lock()
+	defer c.Unlock()
+	c.memoStats = stats
+}
+
+// ParserMemoStats represents a memoization table
+// statistics collected during the last Parser.Parse call.
+type ParserMemoStats struct {
+	Hits   int
+	Misses int
+	Size   int
+}
+
+// parserMemoKey identifies a Rule application at
+// the specific position in the input.
+// Results with and without error recovery differ,
+// so they are distinguished too.
+type parserMemoKey struct {
+	rule       Rule
+	position   int
+	recovering bool
+}
+
+// parserMemoEntry is a memoized result of the Rule application.
+type parserMemoEntry struct {
+	tree    *Tree
+	err     error
+	failure parserFailure
+}
+
+// parserFailure is the farthest location in the input
+// where finite rules failed, with rules expected there.
+type parserFailure struct {
+	location *Location
+	expected Rules
+}
+
+// add records a failure of the rule at the location.
+func (f *parserFailure) add(rule Rule, location *Location) {
+	switch {
+	case f.location == nil || location.Position > f.location.Position:
+		f.location = location
+		f.expected = Rules{rule}
+	case location.Position == f.location.Position:
+		for _, expected := range f.expected {
+			if expected == rule {
+				return
+			}
+		}
+		f.expected = append(f.expected, rule)
+	}
+}
+
+// merge records failures from other.
+func (f *parserFailure) merge(other parserFailure) {
+	for _, rule := range other.expected {
+		f.add(rule, other.location)
+	}
+}
+
+// parserCall is a Rule application which is in progress.
+// It is used to detect left recursion and grow the seed.
+type parserCall struct {
+	key      parserMemoKey
+	index    int
+	seed     *parserMemoEntry
+	head     bool // left recursion detected for this call
+	involved bool // result depends on the seed of some head
 }
 
 // ParserOption represents a Parser option
@@ -59,14 +198,46 @@ func ParserOptionPath(path string) ParserOption {
 	return func(p *Parser) { p.Path = path }
 }
 
+// ParserOptionMemoize enables packrat memoization of every
+// (Rule, position) result during Parser.Parse.
+// This guarantees linear parse time at the cost of memory,
+// memoized results are reused without calling Rule hooks again.
+// Memoization is not used when Context.State is Transactional.
+func ParserOptionMemoize(enabled bool) ParserOption {
+	return func(p *Parser) { p.Memoize = enabled }
+}
+
+// ParserOptionPredict enables Either alternatives prediction
+// with FIRST sets computed by Analyze for the rule passed to Parser.Parse.
+// Alternatives which could not start with the next input byte are skipped.
+// Analysis is cached per rule, so rules should not be changed between parses.
+func ParserOptionPredict(enabled bool) ParserOption {
+	return func(p *Parser) { p.Predict = enabled }
+}
+
+// ParserOptionRecover enables error recovery with synchronization
+// rules of Chain & Repetition (see Chain.Sync, Repetition.Sync).
+// Parser.Parse returns best-effort Tree with error nodes (see Tree.Err)
+// together with ErrorList of all errors which were recovered.
+// Recovery is a last resort: input is parsed without recovery first,
+// so valid input yields the same Tree. Input which fails is parsed again
+// with recovery, Transactional state is restored before that,
+// other state will see hooks of both attempts.
+// While recovering Either, Optional, Repetition & SepBy still try
+// their sub-rules without recovery first and predicates never recover.
+func ParserOptionRecover(enabled bool) ParserOption {
+	return func(p *Parser) { p.Recover = enabled }
+}
+
 // LineRegions construct a slice of Region's for given input.
 // This regions contains ranges of non line-break symbols from left to right.
-// Return value could be used as Parser.LineIndex.
-// Also Parser.Parse calls Parser.LineRegions for you automatically.
+// Return value could be used with LocateRegions.
+// Also Parser.Parse calls Parser.LineRegions for you automatically
+// and keeps the result in the session (see Parser.Locate).
 func (p *Parser) LineRegions(input []byte) []*Region {
 	loc := &Location{Path: p.Path}
 	ctx := &Context{
-		Parser:   p,
+		Parser:   p.newSession(), // NOTE: line-break rule is not memoized
 		Location: loc,
 	}
 
@@ -104,13 +275,40 @@ func (p *Parser) LineRegions(input []byte) []*Region {
 	return regions
 }
 
-// Locate finds a line & column of the given position.
-// It expects Parser.LineIndex to be a sorted slice of Region's
-// of non line-break's.
-// If there is no LineIndex then it returns 0, 0.
+// recovering returns true if error recovery is enabled
+// and it is not suppressed (see Either).
+func (p *Parser) recovering() bool {
+	return p.Recover && p.noRecover == 0
+}
+
+// predict returns false if rule could not match the input
+// according to the FIRST set computed for the rule passed to Parser.Parse.
+// It always returns true if prediction is disabled (see ParserOptionPredict).
+func (p *Parser) predict(rule Rule, input []byte) bool {
+	if p.analysis == nil || len(input) == 0 {
+		return true
+	}
+	return p.analysis.Nullable(rule) || p.analysis.First(rule).Has(input[0])
+}
+
+// Locate finds a line & column of the given position
+// in the input of the current Parser.Parse call,
+// so it should be called on Context.Parser which is the session of the call.
+// Parser which is not a session uses deprecated Parser.LineIndex.
 func (p *Parser) Locate(position int) (int, int) {
+	if p.session {
+		return LocateRegions(p.lineIndex, position)
+	}
+	return LocateRegions(p.LineIndex, position)
+}
+
+// LocateRegions finds a line & column of the given position.
+// It expects lineIndex to be a sorted slice of Region's
+// of non line-break's (see Parser.LineRegions).
+// If there is no lineIndex then it returns 0, position.
+func LocateRegions(lineIndex []*Region, position int) (int, int) {
 	var (
-		il   = len(p.LineIndex)
+		il   = len(lineIndex)
 		h, t = 0, il - 1
 		l    int
 		c    int
@@ -122,16 +320,16 @@ func (p *Parser) Locate(position int) (int, int) {
 		return l, position
 	}
 	if il == 1 { // returrning position, scoped to region (one line-break in index)
-		if position > p.LineIndex[0].End {
-			return l, p.LineIndex[0].End
+		if position > lineIndex[0].End {
+			return l, lineIndex[0].End
 		}
 		return l, position
 	}
 
 	for h <= t {
 		l = (h + t) / 2
-		if position >= p.LineIndex[l].Start {
-			if position <= p.LineIndex[l].End {
+		if position >= lineIndex[l].Start {
+			if position <= lineIndex[l].End {
 				break
 			} else {
 				h = l + 1
@@ -140,11 +338,11 @@ func (p *Parser) Locate(position int) (int, int) {
 			t = l - 1
 		}
 	}
-	if position > p.LineIndex[l].End {
+	if position > lineIndex[l].End {
 		// handle case when position is larger than available regions
-		c = p.LineIndex[l].End - p.LineIndex[l].Start
+		c = lineIndex[l].End - lineIndex[l].Start
 	} else {
-		c = position - p.LineIndex[l].Start
+		c = position - lineIndex[l].Start
 		if c < 0 {
 			// handle case where position points to line-break
 			c = 0
@@ -155,19 +353,84 @@ func (p *Parser) Locate(position int) (int, int) {
 }
 
 // Parse parses input with Rule's.
-// Calls Parser.LineRegions and store result under Parser.LineIndex.
-// Not safe for concurrent use (and not expected to be used concurrently).
+// Calls Parser.LineRegions and keeps the result in the session
+// which is available to rules & hooks as Context.Parser (see Parser.Locate).
+// Safe for concurrent use.
 func (p *Parser) Parse(r Rule, input []byte) (*Tree, error) {
+	return p.ParseState(r, input, nil)
+}
+
+// ParseState parses input with Rule's like Parse does,
+// state is available to rules & hooks as Context.State.
+func (p *Parser) ParseState(r Rule, input []byte, state interface{}) (*Tree, error) {
 	if r == nil {
 		return nil, NewErrEmptyRule(r, nil)
 	}
 
-	p.LineIndex = p.LineRegions(input)
+	lineIndex := p.LineRegions(input)
+	if p.Recover {
+		// NOTE: error recovery is a last resort, input is parsed
+		// without it first, so valid input yields the same Tree
+		snapshot := (&Context{State: state}).snapshot()
+		s := p.newParseSession(r, lineIndex)
+		s.noRecover++
+		tree, err := s.parse(r, input, state)
+		s.getCache().storeMemoStats(s)
+		if err == nil {
+			return tree, nil
+		}
+		snapshot.restore()
+	}
+
+	s := p.newParseSession(r, lineIndex)
+	defer s.getCache().storeMemoStats(s)
+
+	return s.parse(r, input, state)
+}
+
+// newParseSession returns a session ready to parse the input
+// with the given line index (see Parser.LineRegions).
+func (p *Parser) newParseSession(r Rule, lineIndex []*Region) *Parser {
+	s := p.newSession()
+	s.lineIndex = lineIndex
+	if p.Predict {
+		s.analysis = s.getCache().analyze(r)
+	}
+	s.memo = map[parserMemoKey]*parserMemoEntry{}
+	s.active = map[parserMemoKey]*parserCall{}
+	return s
+}
+
+// newSession returns a copy of the Parser without per-call state
+// (line index, memoization tables, failures and so on)
+// which shares caches with the Parser.
+// Session is used to parse a single input, rules receive it as Context.Parser.
+func (p *Parser) newSession() *Parser {
+	s := &Parser{
+		MaxDepth:  p.MaxDepth,
+		LineBreak: p.LineBreak,
+		Path:      p.Path,
+		Memoize:   p.Memoize,
+		Predict:   p.Predict,
+		Recover:   p.Recover,
+		session:   true,
+	}
+	s.cache.Store(p.getCache())
+	return s
+}
+
+// parse applies r to the input within the session.
+func (p *Parser) parse(r Rule, input []byte, state interface{}) (*Tree, error) {
 	loc := &Location{Path: p.Path}
-	tree, err := r.Parse(&Context{
-		Parser:   p,
-		Location: loc,
-	}, input)
+	tree, err := p.ParseRule(
+		r,
+		&Context{
+			Parser:   p,
+			Location: loc,
+			State:    state,
+		},
+		input,
+	)
 	if err != nil {
 		if err == ErrSkipRule {
 			return nil, NewErrUnexpectedEOF(r, loc)
@@ -175,23 +438,187 @@ func (p *Parser) Parse(r Rule, input []byte) (*Tree, error) {
 		return nil, err
 	}
 
-	if tree.Region.End < utf8.RuneCount(input) {
+	errs := ErrorList{}
+	if p.Recover {
+		errs = tree.Errors()
+	}
+
+	if tree.Region.End < len(input) {
 		pos := tree.Region.End
-		line, col := p.Locate(pos)
+		if failure := p.failure; failure.location != nil && failure.location.Position >= pos {
+			// NOTE: some rule failed at or after the end of the tree,
+			// it tells better what was expected than unmatched input
+			err = NewErrUnexpectedToken(
+				r,
+				failure.location,
+				ShowInput(input[failure.location.Position:]),
+				NewErrExpected(failure.expected...),
+			)
+		} else {
+			line, col := p.Locate(pos)
+			err = NewErrUnexpectedToken(
+				r,
+				&Location{
+					Path:     p.Path,
+					Position: pos,
+					Line:     line,
+					Column:   col,
+				},
+				ShowInput(input[pos:]),
+				NewErrUnmatchedInput(input[tree.Region.End:]),
+			)
+		}
+		if !p.Recover {
+			return nil, err
+		}
+		errs.Add(err)
+	}
+	errs.Sort()
+
+	return tree, errs.Err()
+}
+
+// ParseRule applies Rule to the input in the given Context.
+// Rules which have sub-rules should use it instead of
+// calling Rule.Parse directly, this way Parser could
+// memoize results when ParserOptionMemoize is enabled
+// and handle left recursion.
+// It should be called on Context.Parser which is
+// a session of the current Parser.Parse call.
+//
+// Left recursion (direct or indirect) is detected when Rule is applied
+// at the position where it is already being applied.
+// Recursive application fails at first, giving other alternatives a chance
+// to produce a seed, then the seed grows while
+// each new application consumes more input than previous one.
+// This yields a left-leaning Tree for left-recursive grammars.
+//
+// Parser also tracks the farthest position where finite rules
+// failed (and which rules were expected there), Either uses it
+// to report what was expected when every alternative fails.
+func (p *Parser) ParseRule(r Rule, ctx *Context, input []byte) (*Tree, error) {
+	key := parserMemoKey{r, ctx.Location.Position, p.recovering()}
+	if call, ok := p.active[key]; ok {
+		p.markLeftRecursion(call)
+		if call.seed != nil {
+			return call.seed.tree, call.seed.err
+		}
 		return nil, NewErrUnexpectedToken(
 			r,
-			&Location{
-				Path:     p.Path,
-				Position: pos,
-				Line:     line,
-				Column:   col,
-			},
-			ShowInput(input[pos:]),
-			NewErrUnmatchedInput(input[tree.Region.End:]),
+			ctx.Location,
+			ShowInput(input),
+			ErrLeftRecursion,
 		)
 	}
 
-	return tree, nil
+	// NOTE: memoized results are reused without calling hooks,
+	// changes they made to Transactional state would be lost
+	_, transactional := ctx.State.(Transactional)
+	memoize := p.Memoize && p.memo != nil && !transactional
+	if memoize {
+		if entry, ok := p.memo[key]; ok {
+			p.memoStats.Hits++
+			p.failure.merge(entry.failure)
+			return rebaseDepth(entry.tree, ctx.Depth), entry.err
+		}
+		p.memoStats.Misses++
+	}
+
+	outer := p.failure
+	p.failure = parserFailure{}
+
+	call := p.pushCall(key)
+	tree, err := r.Parse(ctx, input)
+	if call.head && err == nil {
+		for {
+			call.seed = &parserMemoEntry{tree: tree, err: err}
+			snapshot := ctx.snapshot()
+			tree, err = r.Parse(ctx, input)
+			if err != nil || tree.Region.End <= call.seed.tree.Region.End {
+				snapshot.restore() // NOTE: application which did not grow the seed is discarded
+				tree, err = call.seed.tree, nil
+				break
+			}
+		}
+	}
+	p.popCall(call)
+
+	if err != nil && err != ErrSkipRule && r.IsFinite() && isErrNoMatch(err) {
+		p.failure.add(r, ctx.Location)
+	}
+	failure := p.failure
+	p.failure = outer
+	p.failure.merge(failure)
+
+	if !memoize || call.involved {
+		// NOTE: results which depend on the seed of left recursion
+		// are not final, they could change while seed grows
+		return tree, err
+	}
+	if _, ok := err.(*ErrNestingTooDeep); !ok {
+		// NOTE: nesting depth depends on the path we came from,
+		// not on the position, so it is not memoized
+		p.memo[key] = &parserMemoEntry{tree, err, failure}
+	}
+	return tree, err
+}
+
+// rebaseDepth returns a copy of the memoized tree
+// which is moved to the given depth, because same rule
+// could be applied at the same position from different depths.
+func rebaseDepth(tree *Tree, depth int) *Tree {
+	if tree == nil || tree.Depth == depth {
+		return tree
+	}
+	shift := depth - tree.Depth
+	var rebase func(t *Tree) *Tree
+	rebase = func(t *Tree) *Tree {
+		c := *t
+		c.Depth += shift
+		if t.Childs != nil {
+			c.Childs = make([]*Tree, len(t.Childs))
+			for k, child := range t.Childs {
+				c.Childs[k] = rebase(child)
+			}
+		}
+		return &c
+	}
+	return rebase(tree)
+}
+
+// pushCall registers a Rule application which is in progress.
+func (p *Parser) pushCall(key parserMemoKey) *parserCall {
+	if p.active == nil {
+		p.active = map[parserMemoKey]*parserCall{}
+	}
+	call := &parserCall{key: key, index: len(p.calls)}
+	p.calls = append(p.calls, call)
+	p.active[key] = call
+	return call
+}
+
+// popCall unregisters a Rule application which is done.
+func (p *Parser) popCall(call *parserCall) {
+	p.calls = p.calls[:call.index]
+	delete(p.active, call.key)
+}
+
+// markLeftRecursion marks call as a left recursion head
+// and every call above it as involved into the recursion.
+func (p *Parser) markLeftRecursion(head *parserCall) {
+	head.head = true
+	for _, call := range p.calls[head.index+1:] {
+		call.involved = true
+	}
+}
+
+// MemoStats returns memoization statistics
+// collected during the last finished Parser.Parse call.
+func (p *Parser) MemoStats() ParserMemoStats {
+	c := p.getCache()
+	c.Lock()
+	defer c.Unlock()
+	return c.memoStats
 }
 
 // Parse is a shortcut to call the DefaultParser.Parse().
@@ -213,11 +640,17 @@ func NewParser(op ...ParserOption) *Parser {
 
 //
 
+// Context describes the Rule application, it is passed
+// to Rule.Parse and hooks. Rule is the parent Rule.
+// State is a user-defined value passed to Parser.ParseState,
+// rules carry it into nested Contexts, so hooks could use it
+// to keep symbol tables, scopes and so on.
 type Context struct {
 	Rule     Rule
 	Parser   *Parser
 	Location *Location
 	Depth    int
+	State    interface{}
 }
 
 // Location represents position in input (posirion, line, column).
@@ -2,6 +2,7 @@ package parse
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
@@ -598,10 +599,9 @@ func TestParserLineBreaksLocate(t *testing.T) {
 	for k, sample := range samples {
 		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
 			regions := sample.parser.LineRegions([]byte(sample.text))
-			sample.parser.LineIndex = regions
 			for n, loc := range sample.locs {
 				t.Run(fmt.Sprintf("%d", n), func(t *testing.T) {
-					line, col := sample.parser.Locate(loc.Position)
+					line, col := LocateRegions(regions, loc.Position)
 					msg := spew.Sdump(loc)
 					_ = msg
 					//spew.Dump(regions)
@@ -624,3 +624,298 @@ func TestParserLineBreaksLocate(t *testing.T) {
 		})
 	}
 }
+
+func TestParserMemoize(t *testing.T) {
+	newGrammar := func(levels int, calls *int) Rule {
+		var rule Rule = NewTerminal(
+			"a", "a",
+			func(ctx *Context, t *Tree) error {
+				*calls++
+				return nil
+			},
+		)
+		for n := 0; n < levels; n++ {
+			rule = NewEither(
+				fmt.Sprintf("level %d", n),
+				NewChain(
+					fmt.Sprintf("level %d with b", n),
+					rule,
+					NewTerminal("b", "b"),
+				),
+				rule,
+			)
+		}
+		return rule
+	}
+
+	samples := []struct {
+		memoize bool
+		levels  int
+		calls   int
+		stats   ParserMemoStats
+	}{
+		{false, 10, 1024, ParserMemoStats{}},
+		{true, 10, 1, ParserMemoStats{Hits: 10, Misses: 31, Size: 31}},
+		{true, 20, 1, ParserMemoStats{Hits: 20, Misses: 61, Size: 61}},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			var (
+				calls  int
+				parser = NewParser(
+					ParserOptionMemoize(sample.memoize),
+					ParserOptionMaxDepth(sample.levels*2+1),
+				)
+				msg = spew.Sdump(k, sample)
+			)
+
+			tree, err := parser.Parse(
+				newGrammar(sample.levels, &calls),
+				[]byte("a"),
+			)
+			assert.Nil(t, err, msg)
+			assert.Equal(t, []byte("a"), tree.Data, msg)
+			assert.Equal(t, sample.calls, calls, msg)
+			assert.Equal(t, sample.stats, parser.MemoStats(), msg)
+		})
+	}
+}
+
+func TestParserMemoizeDepth(t *testing.T) {
+	var (
+		a    = NewTerminal("a", "a")
+		rule = NewEither(
+			"either",
+			NewChain("a b", NewChain("nested a", a), NewTerminal("b", "b")),
+			NewChain("a c", a, NewTerminal("c", "c")),
+		)
+	)
+
+	tree, err := NewParser().Parse(rule, []byte("ac"))
+	assert.Nil(t, err)
+	memoized, err := NewParser(ParserOptionMemoize(true)).Parse(rule, []byte("ac"))
+	assert.Nil(t, err)
+	assert.Equal(t, tree, memoized)
+	assert.Equal(t, 1, memoized.Childs[0].Depth)
+}
+
+func TestParserLeftRecursion(t *testing.T) {
+	newDirect := func() Rule {
+		term := NewRegexp("term", "^[0-9]")
+		expr := NewEither("expr")
+		expr.Add(
+			NewChain("add", expr, NewTerminal("+", "+"), term),
+			NewChain("sub", expr, NewTerminal("-", "-"), term),
+			term,
+		)
+		return expr
+	}
+	newIndirect := func() Rule {
+		list := NewEither("list")
+		item := NewWrapper("item", list)
+		list.Add(
+			NewChain("items", item, NewTerminal(",", ","), NewTerminal("x", "x")),
+			NewTerminal("x", "x"),
+		)
+		return list
+	}
+	// show folds a tree into s-expression-like string
+	// to check associativity.
+	var show func(t *Tree) string
+	show = func(t *Tree) string {
+		switch len(t.Childs) {
+		case 0:
+			return string(t.Data)
+		case 1:
+			return show(t.Childs[0])
+		}
+		s := "("
+		for k, child := range t.Childs {
+			if k > 0 {
+				s += " "
+			}
+			s += show(child)
+		}
+		return s + ")"
+	}
+
+	samples := []struct {
+		text    string
+		rule    Rule
+		memoize bool
+		result  string
+	}{
+		{"1", newDirect(), false, "1"},
+		{"1+2", newDirect(), false, "(1 + 2)"},
+		{"1+2-3+4", newDirect(), false, "(((1 + 2) - 3) + 4)"},
+		{"1+2-3+4", newDirect(), true, "(((1 + 2) - 3) + 4)"},
+		{"x", newIndirect(), false, "x"},
+		{"x,x,x", newIndirect(), false, "((x , x) , x)"},
+		{"x,x,x", newIndirect(), true, "((x , x) , x)"},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample.text, sample.memoize)
+			tree, err := NewParser(ParserOptionMemoize(sample.memoize)).Parse(
+				sample.rule,
+				[]byte(sample.text),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, sample.result, show(tree), msg)
+			assert.Equal(t, []byte(sample.text), tree.Data, msg)
+		})
+	}
+
+	_, err := Parse(newDirect(), []byte("1+"))
+	assert.IsType(t, &ErrUnexpectedToken{}, err)
+}
+
+func TestParserState(t *testing.T) {
+	type symbols map[string]bool
+
+	var (
+		identifier = func(name string) *Regexp { return NewRegexp(name, "^[a-zA-Z]+") }
+		typeName   = identifier("type name")
+		typedef    = NewChain(
+			"typedef",
+			NewTerminal("typedef", "typedef "),
+			identifier("name"),
+			NewTerminal(";", ";"),
+			func(ctx *Context, tree *Tree) error {
+				ctx.State.(symbols)[string(tree.Childs[1].Data)] = true
+				return nil
+			},
+		)
+		// declaration & multiplication are ambiguous in C-like languages: `T*x;`,
+		// declaration is chosen only if T is a type name
+		declaration    = NewChain("declaration", typeName, NewTerminal("*", "*"), identifier("name"), NewTerminal(";", ";"))
+		multiplication = NewChain("multiplication", identifier("lhs"), NewTerminal("*", "*"), identifier("rhs"), NewTerminal(";", ";"))
+		program        = NewRepetition("program", NewEither("statement", typedef, declaration, multiplication))
+	)
+	typeName.Hooks = []RuleParseHook{
+		func(ctx *Context, tree *Tree) error {
+			if !ctx.State.(symbols)[string(tree.Data)] {
+				return fmt.Errorf("%q is not a type name", tree.Data)
+			}
+			return nil
+		},
+	}
+
+	state := symbols{}
+	tree, err := NewParser().ParseState(program, []byte("a*b;typedef T;T*x;a*c;"), state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statements := []string{}
+	for _, statement := range tree.Childs {
+		statements = append(statements, statement.Childs[0].Name())
+	}
+	assert.Equal(t, []string{"multiplication", "typedef", "declaration", "multiplication"}, statements)
+	assert.Equal(t, symbols{"T": true}, state)
+}
+
+func TestParserConcurrent(t *testing.T) {
+	var (
+		number = NewRegexp("number", "^[0-9]+")
+		line   = NewChain("line", NewTerminal("=", "="), number, NewTerminal("lf", "\n"))
+		lines  = NewRepetitionTimesVariadic("lines", 0, line)
+		parser = NewParser(
+			ParserOptionMemoize(true),
+			ParserOptionPredict(true),
+		)
+		wg sync.WaitGroup
+	)
+
+	for n := 0; n < 32; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			msg := spew.Sdump(n)
+
+			input := []byte{}
+			for k := 0; k <= n; k++ {
+				input = append(input, fmt.Sprintf("=%d\n", k)...)
+			}
+			tree, err := parser.Parse(lines, input)
+			assert.Nil(t, err, msg)
+			assert.Len(t, tree.Childs, n+1, msg)
+
+			input = append(input, "=?\n"...)
+			_, err = parser.Parse(lines, input)
+			loc := ErrorLocation(err)
+			if assert.NotNil(t, loc, msg) {
+				assert.Equal(t, n+1, loc.Line, msg)
+				assert.Equal(t, 1, loc.Column, msg)
+			}
+
+			tree, err = Parse(lines, []byte(fmt.Sprintf("=%d\n", n)))
+			assert.Nil(t, err, msg)
+			assert.Len(t, tree.Childs, 1, msg)
+		}(n)
+	}
+	wg.Wait()
+}
+
+func TestParserSessionLocate(t *testing.T) {
+	var (
+		locs []*Location
+		word = NewRegexp("word", "^[a-z]+", func(ctx *Context, tree *Tree) error {
+			line, col := ctx.Parser.Locate(tree.Region.Start)
+			locs = append(locs, &Location{Position: tree.Region.Start, Line: line, Column: col})
+			return nil
+		})
+		words  = NewSepBy1("words", word, NewTerminal("lf", "\n"))
+		parser = &Parser{MaxDepth: DefaultParserMaxDepth, LineBreak: DefaultParserLineBreak, Memoize: true}
+	)
+
+	_, err := parser.Parse(words, []byte("foo\nbar\nbaz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []*Location{
+		{Position: 0, Line: 0, Column: 0},
+		{Position: 4, Line: 1, Column: 0},
+		{Position: 8, Line: 2, Column: 0},
+	}, locs)
+	assert.Equal(t, ParserMemoStats{Misses: 7, Size: 7}, parser.MemoStats())
+}
+
+func TestParserUnmatchedExpected(t *testing.T) {
+	var (
+		number     = NewRegexp("number", "^[0-9]+")
+		identifier = NewRegexp("identifier", "^[a-z]+")
+		expr       = NewEither("expr")
+		atom       = NewEither(
+			"atom",
+			number,
+			NewChain("group", NewTerminal("(", "("), expr, NewTerminal(")", ")")),
+			identifier,
+		)
+		add = NewChain("add", atom, NewTerminal("+", "+"), expr)
+	)
+	expr.Add(add, atom)
+
+	samples := []struct {
+		text     string
+		position int
+		expected string
+	}{
+		{"1+*", 2, `expected one of: number, "(", identifier`},
+		{"(1+2)*", 5, `expected "+"`},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample)
+			_, err := NewParser().Parse(expr, []byte(sample.text))
+			assert.IsType(t, &ErrUnexpectedToken{}, err, msg)
+			if err == nil {
+				return
+			}
+			unexpected := err.(*ErrUnexpectedToken)
+			assert.Equal(t, sample.position, unexpected.Location.Position, msg)
+			assert.Equal(t, sample.expected, unexpected.Inner[0].Error(), msg)
+		})
+	}
+}
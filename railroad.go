@@ -0,0 +1,398 @@
+package parse
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	railroadPadding    = 20
+	railroadGap        = 10
+	railroadRadius     = 10
+	railroadBoxHeight  = 24
+	railroadBoxPadding = 10
+	railroadCharWidth  = 8
+	railroadStyle      = `path{fill:none;stroke:#333;stroke-width:2}` +
+		`rect{fill:#fff;stroke:#333;stroke-width:2}` +
+		`rect.terminal{fill:#ffd}` +
+		`rect.special{fill:#eee}` +
+		`text{font:13px monospace;text-anchor:middle}` +
+		`text.label{font:11px monospace}`
+)
+
+// RailroadDiagram represents a railroad (syntax) diagram
+// of the single named Rule.
+type RailroadDiagram struct {
+	Name string
+	Rule Rule
+	SVG  string
+}
+
+// Railroad renders standalone SVG railroad diagrams for the Rule graph,
+// one diagram per named Chain, Either, Repetition and other non-finite rules
+// reachable from the rule (which is always the first diagram).
+// Terminal and Regexp are drawn as leaf boxes, non-finite rules inside diagram
+// are drawn as boxes referring to diagrams by name.
+func Railroad(rule Rule) []*RailroadDiagram {
+	rules, names := ruleProductions(rule, func(name string) string { return name })
+	r := &railroadRenderer{names: names}
+
+	diagrams := make([]*RailroadDiagram, len(rules))
+	for k, rule := range rules {
+		diagrams[k] = &RailroadDiagram{
+			Name: names[rule],
+			Rule: rule,
+			SVG:  railroadSVG(names[rule], r.body(rule)),
+		}
+	}
+	return diagrams
+}
+
+//
+
+// railroadNode is an element of the railroad diagram.
+// Each element is drawn on the rail line at y,
+// it occupies up pixels above the line and down pixels below.
+type railroadNode interface {
+	size() (width, up, down int)
+	draw(b *strings.Builder, x, y int)
+}
+
+// railroadBox is a leaf box with a text inside.
+type railroadBox struct {
+	text  string
+	class string
+}
+
+func (n *railroadBox) size() (int, int, int) {
+	width := utf8.RuneCountInString(n.text)*railroadCharWidth + 2*railroadBoxPadding
+	return width, railroadBoxHeight / 2, railroadBoxHeight / 2
+}
+
+func (n *railroadBox) draw(b *strings.Builder, x, y int) {
+	width, up, _ := n.size()
+	rx := 0
+	if n.class == "terminal" {
+		rx = railroadBoxHeight / 2
+	}
+	fmt.Fprintf(
+		b, `<rect class=%q x="%d" y="%d" width="%d" height="%d" rx="%d"/>`,
+		n.class, x, y-up, width, railroadBoxHeight, rx,
+	)
+	fmt.Fprintf(
+		b, `<text x="%d" y="%d">%s</text>`,
+		x+width/2, y+4, html.EscapeString(n.text),
+	)
+}
+
+// railroadSequence is a sequence of elements, drawn left to right.
+type railroadSequence []railroadNode
+
+func (n railroadSequence) size() (int, int, int) {
+	var width, up, down int
+	for k, item := range n {
+		w, u, d := item.size()
+		if k > 0 {
+			width += railroadGap
+		}
+		width += w
+		up = maxInt(up, u)
+		down = maxInt(down, d)
+	}
+	return width, up, down
+}
+
+func (n railroadSequence) draw(b *strings.Builder, x, y int) {
+	for k, item := range n {
+		if k > 0 {
+			railroadLine(b, x, y, x+railroadGap, y)
+			x += railroadGap
+		}
+		item.draw(b, x, y)
+		w, _, _ := item.size()
+		x += w
+	}
+}
+
+// railroadChoice is a set of alternatives, drawn top to bottom,
+// first alternative is on the rail line.
+type railroadChoice []railroadNode
+
+func (n railroadChoice) size() (int, int, int) {
+	width, up, down := n[0].size()
+	for _, item := range n[1:] {
+		w, itemUp, itemDown := item.size()
+		width = maxInt(width, w)
+		down += railroadGap + itemUp + itemDown
+	}
+	return width + 4*railroadRadius, up, down
+}
+
+func (n railroadChoice) draw(b *strings.Builder, x, y int) {
+	var (
+		r            = railroadRadius
+		width, _, _  = n.size()
+		inner        = width - 4*r
+		_, _, bottom = n[0].size()
+		itemY        = y
+	)
+	for k, item := range n {
+		w, up, down := item.size()
+		if k > 0 {
+			itemY += bottom + railroadGap + up
+			bottom = down
+			fmt.Fprintf(
+				b, `<path d="M%d %d q%d 0 %d %d v%d q0 %d %d %d"/>`,
+				x, y, r, r, r, itemY-y-2*r, r, r, r,
+			)
+			fmt.Fprintf(
+				b, `<path d="M%d %d q%d 0 %d %d v%d q0 %d %d %d"/>`,
+				x+2*r+inner, itemY, r, r, -r, -(itemY - y - 2*r), -r, r, -r,
+			)
+		} else {
+			railroadLine(b, x, y, x+2*r, y)
+			railroadLine(b, x+width-2*r, y, x+width, y)
+		}
+		item.draw(b, x+2*r, itemY)
+		railroadLine(b, x+2*r+w, itemY, x+2*r+inner, itemY)
+	}
+}
+
+// railroadLoop is an element which could be repeated,
+// optionally it could be skipped.
+type railroadLoop struct {
+	item  railroadNode
+	label string
+	skip  bool
+}
+
+func (n *railroadLoop) size() (int, int, int) {
+	width, up, down := n.item.size()
+	down += railroadGap + railroadRadius
+	if n.label != "" {
+		down += railroadBoxHeight / 2
+	}
+	if n.skip {
+		up += railroadGap + railroadRadius
+	}
+	return width + 4*railroadRadius, up, down
+}
+
+func (n *railroadLoop) draw(b *strings.Builder, x, y int) {
+	var (
+		r                 = railroadRadius
+		width, up, down   = n.item.size()
+		loopY             = y + down + railroadGap
+		skipY             = y - up - railroadGap
+		right             = x + width + 4*r
+		itemLeft, itemEnd = x + 2*r, x + 2*r + width
+	)
+	railroadLine(b, x, y, itemLeft, y)
+	n.item.draw(b, itemLeft, y)
+	railroadLine(b, itemEnd, y, right, y)
+
+	fmt.Fprintf(
+		b, `<path d="M%d %d q%d 0 %d %d v%d q0 %d %d %d H%d q%d 0 %d %d v%d q0 %d %d %d"/>`,
+		itemEnd, y, r, r, r, loopY-y-2*r, r, -r, r,
+		itemLeft, -r, -r, -r, -(loopY - y - 2*r), -r, r, -r,
+	)
+	if n.label != "" {
+		fmt.Fprintf(
+			b, `<text class="label" x="%d" y="%d">%s</text>`,
+			x+(right-x)/2, loopY+railroadBoxHeight/2, html.EscapeString(n.label),
+		)
+	}
+	if n.skip {
+		railroadSkip(b, x, y, right, skipY)
+	}
+}
+
+// railroadOptional is an element which could be skipped.
+type railroadOptional struct {
+	item railroadNode
+}
+
+func (n *railroadOptional) size() (int, int, int) {
+	width, up, down := n.item.size()
+	return width + 4*railroadRadius, up + railroadGap + railroadRadius, down
+}
+
+func (n *railroadOptional) draw(b *strings.Builder, x, y int) {
+	var (
+		r            = railroadRadius
+		width, up, _ = n.item.size()
+		right        = x + width + 4*r
+	)
+	railroadLine(b, x, y, x+2*r, y)
+	n.item.draw(b, x+2*r, y)
+	railroadLine(b, x+2*r+width, y, right, y)
+	railroadSkip(b, x, y, right, y-up-railroadGap)
+}
+
+// railroadSkip draws a bypass line above the element
+// which starts at left and ends at right on the rail line y.
+func railroadSkip(b *strings.Builder, left, y, right, skipY int) {
+	r := railroadRadius
+	fmt.Fprintf(
+		b, `<path d="M%d %d q%d 0 %d %d v%d q0 %d %d %d H%d q%d 0 %d %d v%d q0 %d %d %d"/>`,
+		left, y, r, r, -r, -(y - skipY - 2*r), -r, r, -r,
+		right-2*r, r, r, r, y-skipY-2*r, r, r, r,
+	)
+}
+
+func railroadLine(b *strings.Builder, x1, y1, x2, y2 int) {
+	if x1 == x2 && y1 == y2 {
+		return
+	}
+	fmt.Fprintf(b, `<path d="M%d %d L%d %d"/>`, x1, y1, x2, y2)
+}
+
+// railroadSVG renders a standalone SVG document with diagram.
+func railroadSVG(name string, node railroadNode) string {
+	var (
+		b               = &strings.Builder{}
+		width, up, down = node.size()
+		total           = width + 2*railroadPadding + 2*railroadGap
+		height          = up + down + 2*railroadPadding
+		x, y            = railroadPadding, railroadPadding + up
+	)
+	fmt.Fprintf(
+		b, `<svg xmlns="http://www.w3.org/2000/svg" class="railroad" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		total, height, total, height,
+	)
+	fmt.Fprintf(b, `<title>%s</title>`, html.EscapeString(name))
+	fmt.Fprintf(b, `<style>%s</style>`, railroadStyle)
+
+	// start & end markers
+	fmt.Fprintf(b, `<path d="M%d %d v%d"/>`, x, y-railroadGap, 2*railroadGap)
+	railroadLine(b, x, y, x+railroadGap, y)
+	node.draw(b, x+railroadGap, y)
+	railroadLine(b, x+railroadGap+width, y, x+2*railroadGap+width, y)
+	fmt.Fprintf(b, `<path d="M%d %d v%d"/>`, x+2*railroadGap+width, y-railroadGap, 2*railroadGap)
+
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+//
+
+// railroadRenderer converts Rule graph into railroad elements.
+type railroadRenderer struct {
+	names map[Rule]string
+}
+
+// body returns a railroad element for the diagram of the rule.
+func (r *railroadRenderer) body(rule Rule) railroadNode {
+	switch v := rule.(type) {
+	case *Chain:
+		return r.sequence(v.Rules)
+	case *Either:
+		if len(v.Rules) == 0 {
+			return r.sequence(nil)
+		}
+		choice := make(railroadChoice, len(v.Rules))
+		for k, sub := range v.Rules {
+			choice[k] = r.node(sub)
+		}
+		return choice
+	case *Repetition:
+		return r.repetition(r.node(v.Rule), v.Times, v.Variadic)
+	case *Optional:
+		return &railroadOptional{r.node(v.Rule)}
+	case *SepBy:
+		return r.sepBy(v)
+	case *Bound:
+		var body railroadNode = &railroadBox{"any", "special"}
+		if v.Rule != nil {
+			body = r.node(v.Rule)
+		}
+		return railroadSequence{r.node(v.Open), body, r.node(v.Close)}
+	case *Wrapper:
+		return r.node(v.Rule)
+	default:
+		if rule.IsFinite() {
+			return r.finite(rule)
+		}
+		items := railroadSequence{&railroadBox{rule.Name(), "special"}}
+		for _, child := range rule.GetChilds() {
+			if sub, ok := child.(Rule); ok {
+				items = append(items, r.node(sub))
+			}
+		}
+		return items
+	}
+}
+
+// sepBy returns a railroad element for the SepBy
+// as a combination of repetitions.
+func (r *railroadRenderer) sepBy(v *SepBy) railroadNode {
+	if v.Trailing == SepByTrailingRequire {
+		return r.repetition(railroadSequence{r.node(v.Rule), r.node(v.Separator)}, v.Min, true)
+	}
+
+	items := railroadSequence{r.node(v.Rule)}
+	if v.Min > 1 {
+		items = append(items, r.repetition(railroadSequence{r.node(v.Separator), r.node(v.Rule)}, v.Min-1, false))
+	}
+	items = append(items, r.repetition(railroadSequence{r.node(v.Separator), r.node(v.Rule)}, 0, true))
+	if v.Trailing == SepByTrailingAllow {
+		items = append(items, &railroadOptional{r.node(v.Separator)})
+	}
+	if v.Min == 0 {
+		return &railroadOptional{items}
+	}
+	return items
+}
+
+func (r *railroadRenderer) sequence(rules Rules) railroadNode {
+	if len(rules) == 0 {
+		return &railroadBox{nilLabel, "special"}
+	}
+	items := make(railroadSequence, len(rules))
+	for k, sub := range rules {
+		items[k] = r.node(sub)
+	}
+	return items
+}
+
+func (r *railroadRenderer) repetition(item railroadNode, times int, variadic bool) railroadNode {
+	switch {
+	case !variadic:
+		return &railroadLoop{item: item, label: strconv.Itoa(times) + "×"}
+	case times == 0:
+		return &railroadLoop{item: item, skip: true}
+	case times == 1:
+		return &railroadLoop{item: item}
+	default:
+		return &railroadLoop{item: item, label: "≥" + strconv.Itoa(times)}
+	}
+}
+
+// node returns a railroad element for the rule inside other diagram.
+func (r *railroadRenderer) node(rule Rule) railroadNode {
+	rule = Unref(rule)
+	if rule == nil {
+		return &railroadBox{nilLabel, "special"}
+	}
+	if !rule.IsFinite() {
+		return &railroadBox{r.names[rule], "reference"}
+	}
+	return r.finite(rule)
+}
+
+func (r *railroadRenderer) finite(rule Rule) railroadNode {
+	switch v := rule.(type) {
+	case *Terminal:
+		if v.Fold {
+			return &railroadBox{strconv.Quote(string(v.Value)) + "i", "terminal"}
+		}
+		return &railroadBox{strconv.Quote(string(v.Value)), "terminal"}
+	case *Regexp:
+		return &railroadBox{"/" + v.Expr + "/", "regexp"}
+	default:
+		return &railroadBox{rule.Name(), "special"}
+	}
+}
@@ -0,0 +1,86 @@
+package parse
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRailroad(t *testing.T) {
+	samples := []struct {
+		rule     Rule
+		diagrams []string
+		texts    [][]string
+	}{
+		{
+			NewTerminal("foo", "foo"),
+			[]string{"foo"},
+			[][]string{{`"foo"`}},
+		},
+		{
+			newTestFormatGrammar(),
+			[]string{"expression", "sum", "tail", "plus number", "group", "pair"},
+			[][]string{
+				{"sum", "group", "pair", "custom"},
+				{"/^[0-9]+/", "tail"},
+				{"plus number"},
+				{`"+"`, "/^[0-9]+/"},
+				{`"("`, "expression", `")"`},
+				{`"\""`, "2×"},
+			},
+		},
+		{
+			NewOptional("maybe", NewTerminal("a", "a")),
+			[]string{"maybe"},
+			[][]string{{`"a"`}},
+		},
+		{
+			NewRepetitionTimesVariadic("at least", 3, NewTerminal("a", "a")),
+			[]string{"at least"},
+			[][]string{{`"a"`, "≥3"}},
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample)
+			diagrams := Railroad(sample.rule)
+
+			names := make([]string, len(diagrams))
+			for n, diagram := range diagrams {
+				names[n] = diagram.Name
+			}
+			assert.Equal(t, sample.diagrams, names, msg)
+
+			for n, diagram := range diagrams {
+				decoder := xml.NewDecoder(strings.NewReader(diagram.SVG))
+				texts := []string{}
+				inText := false
+				for {
+					token, err := decoder.Token()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						t.Fatalf("invalid svg %q: %s", diagram.SVG, err)
+					}
+					switch v := token.(type) {
+					case xml.StartElement:
+						inText = v.Name.Local == "text"
+					case xml.EndElement:
+						inText = false
+					case xml.CharData:
+						if inText {
+							texts = append(texts, string(v))
+						}
+					}
+				}
+				assert.Equal(t, sample.texts[n], texts, msg)
+			}
+		})
+	}
+}
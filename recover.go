@@ -0,0 +1,96 @@
+package parse
+
+import (
+	"unicode/utf8"
+)
+
+// recoverSync skips input (which starts at position start) up to
+// the end of the first non-empty match of any sync rule and returns
+// an error Tree which covers skipped input, see Parser.Recover.
+// The search starts at the position of the err, so partially matched
+// input is skipped too.
+// It returns nil if recovery is disabled (or suppressed), err could not be recovered
+// or there is no sync rule match till the end of input.
+func recoverSync(ctx *Context, r Rule, sync Rules, failed Rule, start int, input []byte, err error) *Tree {
+	if !ctx.Parser.recovering() || len(sync) == 0 || !isErrNoMatch(err) || err == ErrSkipRule {
+		return nil
+	}
+
+	pos, ok := errorPosition(err)
+	if !ok || pos < start {
+		pos = start
+	}
+	if pos > start+len(input) {
+		pos = start + len(input)
+	}
+
+	var (
+		nextDepth = ctx.Depth + 1
+		line, col int
+		syncTree  *Tree
+		syncErr   error
+		failure   = ctx.Parser.failure
+	)
+	defer func() { ctx.Parser.failure = failure }() // NOTE: sync rules are not expected
+	for pos < start+len(input) {
+		line, col = ctx.Parser.Locate(pos)
+		for _, sr := range sync {
+			snapshot := ctx.snapshot()
+			syncTree, syncErr = ctx.Parser.ParseRule(
+				sr,
+				&Context{
+					Rule:   r,
+					Parser: ctx.Parser,
+					Location: &Location{
+						Path:     ctx.Location.Path,
+						Position: pos,
+						Line:     line,
+						Column:   col,
+					},
+					Depth: nextDepth + 1,
+					State: ctx.State,
+				},
+				input[pos-start:],
+			)
+			if syncErr == nil && syncTree.Region.End > pos {
+				goto found
+			}
+			snapshot.restore()
+			if syncErr != nil && !isErrNoMatch(syncErr) {
+				return nil
+			}
+		}
+		_, size := utf8.DecodeRune(input[pos-start:])
+		pos += size
+	}
+	return nil
+
+found:
+	line, col = ctx.Parser.Locate(start)
+	return &Tree{
+		Rule: failed,
+		Location: &Location{
+			Path:     ctx.Location.Path,
+			Position: start,
+			Line:     line,
+			Column:   col,
+		},
+		Region: &Region{
+			Start: start,
+			End:   syncTree.Region.End,
+		},
+		Depth:  nextDepth,
+		Childs: []*Tree{syncTree},
+		Data:   input[:syncTree.Region.End-start],
+		Err:    err,
+	}
+}
+
+// errorPosition returns a position of the input
+// where err was found if err carries a Location.
+func errorPosition(err error) (int, bool) {
+	if loc := ErrorLocation(err); loc != nil {
+		return loc.Position, true
+	}
+	return 0, false
+}
@@ -0,0 +1,188 @@
+package parse
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParserRecover(t *testing.T) {
+	newGrammar := func(chainSync bool, repetitionSync bool) Rule {
+		semicolon := NewTerminal(";", ";")
+		statement := NewChain(
+			"statement",
+			NewRegexp("identifier", "^[a-z]+"),
+			NewTerminal("=", "="),
+			NewRegexp("number", "^[0-9]+"),
+			semicolon,
+		)
+		if chainSync {
+			statement.WithSync(semicolon)
+		}
+		program := NewRepetition("program", statement)
+		if repetitionSync {
+			program.WithSync(semicolon)
+		}
+		return program
+	}
+	// show lists data of the program statements,
+	// error nodes are marked with `!`.
+	show := func(tree *Tree) []string {
+		if tree == nil {
+			return nil
+		}
+		statements := []string{}
+		for _, statement := range tree.Childs {
+			if statement.Err != nil {
+				statements = append(statements, "!"+string(statement.Data))
+				continue
+			}
+			s := ""
+			for _, child := range statement.Childs {
+				if child.Err != nil {
+					s += "!"
+				}
+				s += string(child.Data)
+			}
+			statements = append(statements, s)
+		}
+		return statements
+	}
+
+	samples := []struct {
+		text           string
+		chainSync      bool
+		repetitionSync bool
+		recover        bool
+		statements     []string
+		errs           int
+	}{
+		{"a=1;b=2;", true, true, true, []string{"a=1;", "b=2;"}, 0},
+		{"a=1;b=?;c=3;", true, false, true, []string{"a=1;", "b=!?;", "c=3;"}, 1},
+		{"a=1;b=?;c=3;", false, true, true, []string{"a=1;", "!b=?;", "c=3;"}, 1},
+		{"a=1;b=?;c;d=4;", false, true, true, []string{"a=1;", "!b=?;", "!c;", "d=4;"}, 2},
+		{"a=1;b=?;c=3;", true, true, false, nil, 1},
+		{"a=1;b=?", true, true, true, []string{"a=1;"}, 1},
+		{"a=1;?;c=3;", true, true, true, []string{"a=1;", "!?;", "c=3;"}, 1},
+		{"?;a=1;", false, true, true, []string{"!?;", "a=1;"}, 1},
+		{"a=1;?;c=3;", true, false, true, []string{"a=1;"}, 1},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample)
+			parser := NewParser(ParserOptionRecover(sample.recover))
+			tree, err := parser.Parse(
+				newGrammar(sample.chainSync, sample.repetitionSync),
+				[]byte(sample.text),
+			)
+			assert.Equal(t, sample.statements, show(tree), msg)
+			if sample.errs == 0 {
+				assert.Nil(t, err, msg)
+				return
+			}
+			assert.NotNil(t, err, msg)
+			if tree != nil {
+				assert.IsType(t, ErrorList{}, err, msg)
+				assert.Len(t, err, sample.errs, msg)
+			}
+		})
+	}
+
+	tree, _ := NewParser(ParserOptionRecover(true)).Parse(
+		newGrammar(false, true),
+		[]byte("a=1;b=?;c=3;"),
+	)
+	node := tree.Childs[1]
+	assert.IsType(t, &ErrUnexpectedToken{}, node.Err)
+	assert.Equal(t, &Region{Start: 4, End: 8}, node.Region)
+	assert.Equal(t, "statement", node.Name())
+	assert.Equal(t, []byte(";"), node.Childs[0].Data)
+	assert.Equal(t, ErrorList{node.Err}, tree.Errors())
+}
+
+func TestParserRecoverLastResort(t *testing.T) {
+	var (
+		semicolon = NewTerminal(";", ";")
+		a         = NewTerminal("a", "a")
+		statement = NewEither(
+			"statement",
+			NewChain("a b", a, NewTerminal("b", "b"), semicolon).WithSync(semicolon),
+			NewChain("a c", a, NewTerminal("c", "c"), semicolon),
+		)
+		longest = NewEitherLongest(
+			"statement",
+			NewChain("a b", a, NewTerminal("b", "b"), semicolon).WithSync(semicolon),
+			NewChain("a c", a, NewTerminal("c", "c"), semicolon),
+		)
+		program = NewRepetition("program", statement).WithSync(semicolon)
+		list    = NewSepBy1(
+			"list",
+			NewChain("a b", a, NewTerminal("b", "b")).WithSync(semicolon),
+			NewTerminal(",", ","),
+		)
+	)
+	list.Trailing = SepByTrailingAllow
+
+	samples := []struct {
+		rule Rule
+		text string
+	}{
+		{statement, "ab;"},
+		{statement, "ac;"},
+		{longest, "ac;"},
+		{program, "ac;ab;ac;"},
+		{
+			NewChain(
+				"optional",
+				NewOptional("a b?", NewChain("a b", a, NewTerminal("b", "b")).WithSync(semicolon)),
+				a, NewTerminal("c", "c"), semicolon,
+			),
+			"ac;",
+		},
+		{
+			NewChain(
+				"repetition",
+				NewRepetition("ab", NewChain("a b", a, NewTerminal("b", "b")).WithSync(semicolon)),
+				a, NewTerminal("c", "c"), semicolon,
+			),
+			"abac;",
+		},
+		{
+			NewChain(
+				"not",
+				NewNot("not if", NewChain("if", NewTerminal("i", "i"), NewTerminal("f", "f")).WithSync(semicolon)),
+				NewRegexp("word", "^[a-z;]+"),
+			),
+			"ix;",
+		},
+		{NewChain("sep by", list, a, NewTerminal("c", "c"), semicolon), "ab,ac;"},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample.text)
+			tree, err := NewParser().Parse(sample.rule, []byte(sample.text))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, memoize := range []bool{false, true} {
+				recovered, err := NewParser(
+					ParserOptionRecover(true),
+					ParserOptionMemoize(memoize),
+				).Parse(sample.rule, []byte(sample.text))
+				assert.Nil(t, err, msg)
+				assert.Equal(t, tree, recovered, msg)
+			}
+		})
+	}
+
+	tree, err := NewParser(ParserOptionRecover(true)).Parse(program, []byte("ac;ax;ab;"))
+	assert.Len(t, err, 1)
+	assert.Len(t, tree.Errors(), 1)
+	data := make([]string, len(tree.Childs))
+	for k, child := range tree.Childs {
+		data[k] = string(child.Data)
+	}
+	assert.Equal(t, []string{"ac;", "ax;", "ab;"}, data)
+}
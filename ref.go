@@ -0,0 +1,96 @@
+package parse
+
+var _ Rule = new(Ref)
+
+// Ref is a Rule which is a forward declared reference
+// to another Rule by name, it makes recursive grammars
+// possible without creating empty rules and filling them later.
+// Target is set by Grammar.Resolve (or manually).
+//
+// Ref is transparent: it yields the Tree of the Target
+// and does not add a node of its own.
+type Ref struct {
+	name   string
+	Target Rule
+}
+
+// Name indicates the name which was given to the rule
+// on creation. Name could be not unique.
+func (r *Ref) Name() string {
+	return r.name
+}
+
+// Show this node as a string.
+// You should provide childs as string
+// to this function, it does not care
+// about nesting in a tree, it only shows
+// string representation of itself.
+func (r *Ref) Show(childs string) string {
+	return RuleShow(
+		r,
+		r.GetParameters().String(),
+		childs,
+	)
+}
+
+// String returns rule as a string,
+// resolving recursion with `<circular>` placeholder.
+func (r *Ref) String() string {
+	return TreerString(r)
+}
+
+// GetChilds returns a slice of Rule which is
+// children for current Rule.
+func (r *Ref) GetChilds() Treers {
+	if r.Target == nil {
+		return nil
+	}
+	return Treers{r.Target}
+}
+
+//
+
+// GetParameters returns a KV rule parameters.
+func (r *Ref) GetParameters() RuleParameters {
+	return RuleParameters{
+		"name": r.name,
+	}
+}
+
+// IsFinite returns true if this rule is
+// not a wrapper for other rules.
+func (r *Ref) IsFinite() bool {
+	return false
+}
+
+// Parse consumes some bytes from input & emits a Tree
+// using settings defined during creation of the concrete Rule type.
+// May return an error if something goes wrong, should provide some
+// location information to the user which points to position in input.
+func (r *Ref) Parse(ctx *Context, input []byte) (*Tree, error) {
+	if r.Target == nil {
+		return nil, NewErrRuleUndefined(r.name)
+	}
+	return ctx.Parser.ParseRule(r.Target, ctx, input)
+}
+
+//
+
+// NewRef constructs new unresolved *Ref to the rule with specified name.
+func NewRef(name string) *Ref {
+	return &Ref{name: name}
+}
+
+// Unref follows Ref chain and returns the referenced Rule.
+// Unresolved (or circular) Ref is returned as is.
+func Unref(rule Rule) Rule {
+	seen := map[*Ref]bool{}
+	for {
+		ref, ok := rule.(*Ref)
+		if !ok || ref.Target == nil || seen[ref] {
+			return rule
+		}
+		seen[ref] = true
+		rule = ref.Target
+	}
+}
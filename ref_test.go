@@ -0,0 +1,69 @@
+package parse
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefShow(t *testing.T) {
+	ref := NewRef("number")
+	assert.Equal(t, "*parse.Ref(name: number)(none)", ref.Show("none"))
+	assert.Nil(t, ref.GetChilds())
+	assert.False(t, ref.IsFinite())
+
+	number := NewRegexp("number", "^[0-9]+")
+	ref.Target = number
+	assert.Equal(t, Treers{number}, ref.GetChilds())
+}
+
+func TestRef(t *testing.T) {
+	number := NewRegexp("number", "^[0-9]+")
+	ref := NewRef("expression")
+	expression := NewEither(
+		"expression",
+		number,
+		NewChain("group", NewTerminal("(", "("), ref, NewTerminal(")", ")")),
+	)
+	ref.Target = expression
+
+	samples := []struct {
+		text string
+		rule Rule
+		err  error
+	}{
+		{"1", ref, nil},
+		{"((1))", expression, nil},
+		{"1", NewRef("undefined"), NewErrRuleUndefined("undefined")},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample.text)
+			tree, err := Parse(sample.rule, []byte(sample.text))
+			assert.EqualValues(t, sample.err, err, msg)
+			if sample.err != nil {
+				return
+			}
+			assert.Equal(t, expression, tree.Rule, msg)
+			assert.Equal(t, []byte(sample.text), tree.Data, msg)
+		})
+	}
+}
+
+func TestUnref(t *testing.T) {
+	number := NewRegexp("number", "^[0-9]+")
+	a, b := NewRef("a"), NewRef("b")
+	a.Target = b
+	b.Target = number
+	assert.Equal(t, number, Unref(a))
+
+	unresolved := NewRef("unresolved")
+	assert.Equal(t, unresolved, Unref(unresolved))
+
+	c := NewRef("c")
+	c.Target = c
+	assert.Equal(t, c, Unref(c))
+	assert.Nil(t, Unref(nil))
+}
@@ -8,10 +8,11 @@ var _ Rule = new(Regexp)
 
 // Regexp is a Rule which should match Go regexp on input.
 type Regexp struct {
-	name   string
-	Regexp *regexp.Regexp
-	Expr   string
-	Hooks  []RuleParseHook
+	name       string
+	Regexp     *regexp.Regexp
+	Expr       string
+	Hooks      []RuleParseHook
+	EnterHooks []RuleEnterHook
 }
 
 // Name indicates the name which was given to the rule
@@ -61,6 +62,10 @@ func (r *Regexp) IsFinite() bool {
 // May return an error if something goes wrong, should provide some
 // location information to the user which points to position in input.
 func (r *Regexp) Parse(ctx *Context, input []byte) (*Tree, error) {
+	if err := runEnterHooks(ctx, r, input, r.EnterHooks); err != nil {
+		return nil, err
+	}
+
 	buf := r.Regexp.Find(input)
 	if buf == nil {
 		return nil, NewErrUnexpectedToken(
@@ -87,8 +92,8 @@ func (r *Regexp) Parse(ctx *Context, input []byte) (*Tree, error) {
 		Depth: ctx.Depth,
 		Data:  buf,
 	}
-	for _, hook := range r.Hooks {
-		hook(ctx, tree)
+	if err := runHooks(ctx, r, tree, r.Hooks); err != nil {
+		return nil, err
 	}
 	return tree, nil
 }
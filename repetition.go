@@ -24,12 +24,21 @@ var ErrRepetitionNothingMatched = fmt.Errorf("nothing matched")
 
 // Repetition is a Rule which is repeating in the input
 // one or more times.
+//
+// Sync rules are used when Parser.Recover is enabled: if
+// an occurrence fails before the end of input (even if it
+// consumed nothing) then input is skipped up to the end of
+// the first Sync match,
+// an error Tree (see Tree.Err) is added to the Repetition childs
+// in place of the failed occurrence and repetition continues.
 type Repetition struct {
-	name     string
-	Rule     Rule
-	Times    int
-	Variadic bool
-	Hooks    []RuleParseHook
+	name       string
+	Rule       Rule
+	Times      int
+	Variadic   bool
+	Sync       Rules
+	Hooks      []RuleParseHook
+	EnterHooks []RuleEnterHook
 }
 
 // Name indicates the Name which was given to the rule
@@ -63,6 +72,13 @@ func (r *Repetition) GetChilds() Treers {
 	return Treers{r.Rule}
 }
 
+// WithSync sets rules which are used to recover
+// from errors and returns the Repetition.
+func (r *Repetition) WithSync(rules ...Rule) *Repetition {
+	r.Sync = rules
+	return r
+}
+
 //
 
 // GetParameters returns a KV rule parameters.
@@ -85,6 +101,10 @@ func (r *Repetition) IsFinite() bool {
 // May return an error if something goes wrong, should provide some
 // location information to the user which points to position in input.
 func (r *Repetition) Parse(ctx *Context, input []byte) (*Tree, error) {
+	if err := runEnterHooks(ctx, r, input, r.EnterHooks); err != nil {
+		return nil, err
+	}
+
 	nextDepth := ctx.Depth + 1
 	if nextDepth > ctx.Parser.MaxDepth {
 		return nil, NewErrNestingTooDeep(ctx.Location, nextDepth)
@@ -97,7 +117,7 @@ func (r *Repetition) Parse(ctx *Context, input []byte) (*Tree, error) {
 		subChilds   = []*Tree{}
 		pos         = ctx.Location.Position
 		line, col   int
-		loc         *Location
+		loc         = ctx.Location
 		err         error
 	)
 repeat:
@@ -113,21 +133,48 @@ repeat:
 			Line:     line,
 			Column:   col,
 		}
-		subTree, err = r.Rule.Parse(
-			&Context{
-				Rule:     r,
-				Parser:   ctx.Parser,
-				Location: loc,
-				Depth:    nextDepth,
-			},
-			subInput,
-		)
+		snapshot := ctx.snapshot()
+		parse := func() (*Tree, error) {
+			return ctx.Parser.ParseRule(
+				r.Rule,
+				&Context{
+					Rule:     r,
+					Parser:   ctx.Parser,
+					Location: loc,
+					Depth:    nextDepth,
+					State:    ctx.State,
+				},
+				subInput,
+			)
+		}
+		if ctx.Parser.recovering() {
+			// NOTE: error recovery is a last resort, the rule
+			// is tried without it first, so repetition could end
+			// where the rule does not match
+			ctx.Parser.noRecover++
+			subTree, err = parse()
+			ctx.Parser.noRecover--
+			if err != nil && err != ErrSkipRule && isErrNoMatch(err) {
+				snapshot.restore()
+				subTree, err = parse()
+			}
+		} else {
+			subTree, err = parse()
+		}
 		if err != nil {
+			snapshot.restore()
 			if err == ErrSkipRule {
 				break
 			}
 			switch err.(type) {
 			case *ErrUnexpectedToken, *ErrUnexpectedEOF:
+				// NOTE: input is not over, so it is skipped to the
+				// sync point even if the rule consumed nothing
+				subTree = recoverSync(ctx, r, r.Sync, r.Rule, pos, subInput, err)
+				if subTree != nil {
+					err = nil
+					break
+				}
 				// XXX: We need to skip current rule
 				// if it has no matches and rule is variadic,
 				// should repeat 0 or more times.
@@ -141,9 +188,15 @@ repeat:
 				return nil, err
 			}
 		}
+		movePos := subTree.Region.End - subTree.Region.Start
+		if movePos == 0 {
+			// NOTE: rule matched empty input (for example Optional),
+			// repeating it will not make any progress
+			snapshot.restore()
+			break
+		}
 		occurrences++
 
-		movePos := subTree.Region.End - subTree.Region.Start
 		if !r.Variadic && occurrences > r.Times {
 			return nil, NewErrUnexpectedToken(
 				r,
@@ -178,6 +231,12 @@ repeat:
 	}
 
 	region := TreeRegion(subChilds...)
+	if len(subChilds) == 0 { // NOTE: input is over, nothing matched
+		region = &Region{
+			Start: ctx.Location.Position,
+			End:   ctx.Location.Position,
+		}
+	}
 	line, col = ctx.Parser.Locate(ctx.Location.Position)
 	tree := &Tree{
 		Rule: r,
@@ -192,8 +251,8 @@ repeat:
 		Childs: subChilds,
 		Data:   input[:region.End-region.Start],
 	}
-	for _, hook := range r.Hooks {
-		hook(ctx, tree)
+	if err := runHooks(ctx, r, tree, r.Hooks); err != nil {
+		return nil, err
 	}
 	return tree, nil
 }
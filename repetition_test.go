@@ -2,7 +2,9 @@ package parse
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/stretchr/testify/assert"
@@ -186,7 +188,11 @@ func TestRepetition(t *testing.T) {
 					Column:   3,
 				},
 				[]byte("4"),
-				NewErrUnmatchedInput([]byte("4")),
+				NewErrExpected(
+					NewTerminal("one", "1"),
+					NewTerminal("two", "2"),
+					NewTerminal("three", "3"),
+				),
 			),
 			DefaultParser,
 		},
@@ -895,3 +901,42 @@ func TestRepetition(t *testing.T) {
 		})
 	}
 }
+
+func TestRepetitionEmptyMatch(t *testing.T) {
+	rules, err := LoadEBNF(strings.NewReader(`g = { [ "x" ] } , "y" ;`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := []struct {
+		rule   Rule
+		text   string
+		childs int
+	}{
+		{NewRepetitionTimesVariadic("r", 0, NewOptional("o", NewTerminal("x", "x"))), "xx", 2},
+		{NewChain("c", NewRepetitionTimesVariadic("r", 0, NewOptional("o", NewTerminal("x", "x"))), NewTerminal("y", "y")), "xy", 2},
+		{rules["g"], "y", 2},
+		{rules["g"], "xxy", 2},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample.text)
+			done := make(chan struct{})
+			var (
+				tree *Tree
+				err  error
+			)
+			go func() {
+				defer close(done)
+				tree, err = Parse(sample.rule, []byte(sample.text))
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("parse did not finish: %s", msg)
+			}
+			assert.Nil(t, err, msg)
+			assert.Equal(t, []byte(sample.text), tree.Data, msg)
+			assert.Len(t, tree.Childs, sample.childs, msg)
+		})
+	}
+}
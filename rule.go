@@ -1,6 +1,7 @@
 package parse
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"sort"
@@ -38,8 +39,18 @@ func (p RuleParameters) String() string {
 
 //
 
+// RuleParseHook is called after the Rule matched the input.
+// Hook could reject the match returning an error, in this case
+// the Rule fails with ErrUnexpectedToken which wraps the error,
+// so other alternatives could be tried (see Either).
+// Error wrapped with NewErrFatal fails the whole parse.
 type RuleParseHook = func(ctx *Context, t *Tree) error
 
+// RuleEnterHook is called before the Rule is applied to the input.
+// Hook could reject the Rule returning an error, which is handled
+// in the same way as RuleParseHook error.
+type RuleEnterHook = func(ctx *Context, input []byte) error
+
 // Rule represents a general Rule interface.
 type Rule interface {
 	Treer
@@ -68,3 +79,42 @@ func RuleShow(rule Rule, parameters string, childs string) string {
 		childs,
 	)
 }
+
+//
+
+// runHooks calls hooks of the rule with the tree it matched.
+// Hook error rejects the match, ErrFatal is returned as is.
+func runHooks(ctx *Context, rule Rule, tree *Tree, hooks []RuleParseHook) error {
+	for _, hook := range hooks {
+		if err := hook(ctx, tree); err != nil {
+			return hookError(rule, tree.Location, tree.Data, err)
+		}
+	}
+	return nil
+}
+
+// runEnterHooks calls enter hooks of the rule with the input.
+// Hook error rejects the rule, ErrFatal is returned as is.
+func runEnterHooks(ctx *Context, rule Rule, input []byte, hooks []RuleEnterHook) error {
+	for _, hook := range hooks {
+		if err := hook(ctx, input); err != nil {
+			return hookError(rule, ctx.Location, input, err)
+		}
+	}
+	return nil
+}
+
+// hookError wraps hook error into ErrUnexpectedToken
+// unless it is ErrFatal.
+func hookError(rule Rule, location *Location, input []byte, err error) error {
+	var fatal *ErrFatal
+	if errors.As(err, &fatal) {
+		return err
+	}
+	return NewErrUnexpectedToken(
+		rule,
+		location,
+		ShowInput(input),
+		err,
+	)
+}
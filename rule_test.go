@@ -1,6 +1,8 @@
 package parse
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
@@ -157,3 +159,96 @@ func TestRuleShow(t *testing.T) {
 		)
 	}
 }
+
+func TestRuleParseHookReject(t *testing.T) {
+	var (
+		errRejected = errors.New("rejected")
+		errFatal    = errors.New("fatal")
+		reject      = func(ctx *Context, tree *Tree) error { return errRejected }
+		fatal       = func(ctx *Context, tree *Tree) error { return NewErrFatal(errFatal) }
+		x           = func(hooks ...RuleParseHook) Rule { return NewTerminal("x", "x", hooks...) }
+	)
+	samples := []struct {
+		rule Rule
+		text string
+	}{
+		{x(reject), "x"},
+		{NewRegexp("x", "^x", reject), "x"},
+		{NewKeywords("x", []string{"x"}, reject), "x"},
+		{NewRuneSet("x", "x", reject), "x"},
+		{NewStringLiteral("x", `"`, reject), `"x"`},
+		{NewChain("x", x(), reject), "x"},
+		{NewEither("x", x(), reject), "x"},
+		{NewRepetition("x", x(), reject), "x"},
+		{NewOptional("x", x(), reject), "x"},
+		{NewWrapper("x", x(), reject), "x"},
+		{NewSepBy("x", x(), NewTerminal(",", ","), reject), "x"},
+		{NewBound("x", NewTerminal("(", "("), x(), NewTerminal(")", ")"), reject), "(x)"},
+		{NewExpression("x", x(), nil, reject), "x"},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample.rule)
+			_, err := Parse(sample.rule, []byte(sample.text))
+			assert.IsType(t, &ErrUnexpectedToken{}, err, msg)
+			assert.True(t, errors.Is(err, errRejected), msg)
+		})
+	}
+
+	reserved := map[string]bool{"if": true}
+	identifier := NewRegexp("identifier", "^[a-z]+", func(ctx *Context, tree *Tree) error {
+		if reserved[string(tree.Data)] {
+			return fmt.Errorf("%q is a reserved word", tree.Data)
+		}
+		return nil
+	})
+	rule := NewEither("token", identifier, NewTerminal("if", "if"))
+	tree, err := Parse(rule, []byte("if"))
+	assert.Nil(t, err)
+	assert.Equal(t, "if", tree.Childs[0].Name())
+
+	_, err = Parse(NewEither("x", x(fatal), x()), []byte("x"))
+	assert.Equal(t, NewErrFatal(errFatal), err)
+	assert.True(t, errors.Is(err, errFatal))
+
+	_, err = Parse(NewRepetition("x", NewEither("x", x(fatal), x())), []byte("xx"))
+	assert.Equal(t, NewErrFatal(errFatal), err)
+}
+
+func TestRuleEnterHook(t *testing.T) {
+	var (
+		entered   = []string{}
+		errDenied = errors.New("denied")
+		enter     = func(name string) RuleEnterHook {
+			return func(ctx *Context, input []byte) error {
+				entered = append(entered, fmt.Sprintf("%s@%d:%s", name, ctx.Location.Position, input))
+				return nil
+			}
+		}
+		deny = func(ctx *Context, input []byte) error {
+			return errDenied
+		}
+		a = NewTerminal("a", "a")
+		b = NewTerminal("b", "b")
+	)
+	a.EnterHooks = []RuleEnterHook{enter("a")}
+	b.EnterHooks = []RuleEnterHook{enter("b")}
+	denied := NewChain("denied", a, b, deny)
+	rule := NewEither("either", denied, NewChain("chain", a, b, enter("chain")), enter("either"))
+
+	tree, err := Parse(rule, []byte("ab"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "chain", tree.Childs[0].Name())
+	assert.Equal(t, []string{"either@0:ab", "chain@0:ab", "a@0:ab", "b@1:b"}, entered)
+
+	_, err = Parse(denied, []byte("ab"))
+	assert.IsType(t, &ErrUnexpectedToken{}, err)
+	assert.True(t, errors.Is(err, errDenied))
+
+	_, err = Parse(NewChain("fatal", a, func(ctx *Context, input []byte) error {
+		return NewErrFatal(errDenied)
+	}), []byte("a"))
+	assert.Equal(t, NewErrFatal(errDenied), err)
+}
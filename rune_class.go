@@ -0,0 +1,328 @@
+package parse
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var _ Rule = new(RuneClass)
+
+// RuneClass is a Rule which matches a single rune
+// from the set of unicode ranges defined by Tables.
+// If Negate is set then it matches any rune which is not in the set.
+// Invalid UTF-8 sequences are never matched.
+type RuneClass struct {
+	name       string
+	Tables     []*unicode.RangeTable
+	Negate     bool
+	Hooks      []RuleParseHook
+	EnterHooks []RuleEnterHook
+}
+
+// Name indicates the name which was given to the rule
+// on creation. Name could be not unique.
+func (r *RuneClass) Name() string {
+	return r.name
+}
+
+// Show this node as a string.
+// You should provide childs as string
+// to this function, it does not care
+// about nesting in a tree, it only shows
+// string representation of itself.
+func (r *RuneClass) Show(childs string) string {
+	return RuleShow(
+		r,
+		r.GetParameters().String(),
+		childs,
+	)
+}
+
+// String returns rule as a string,
+// resolving recursion with `<circular>` placeholder.
+func (r *RuneClass) String() string {
+	return TreerString(r)
+}
+
+// GetChilds returns a slice of Rule which is
+// children for current Rule.
+func (r *RuneClass) GetChilds() Treers {
+	return nil
+}
+
+//
+
+// GetParameters returns a KV rule parameters.
+func (r *RuneClass) GetParameters() RuleParameters {
+	return RuleParameters{
+		"name":   r.name,
+		"class":  r.Class(),
+		"negate": r.Negate,
+	}
+}
+
+// IsFinite returns true if this rule is
+// not a wrapper for other rules.
+func (r *RuneClass) IsFinite() bool {
+	return true
+}
+
+// Parse consumes some bytes from input & emits a Tree
+// using settings defined during creation of the concrete Rule type.
+// May return an error if something goes wrong, should provide some
+// location information to the user which points to position in input.
+func (r *RuneClass) Parse(ctx *Context, input []byte) (*Tree, error) {
+	if err := runEnterHooks(ctx, r, input, r.EnterHooks); err != nil {
+		return nil, err
+	}
+
+	if len(r.Tables) == 0 {
+		return nil, NewErrEmptyRule(r, ctx.Rule)
+	}
+	if len(input) == 0 {
+		return nil, NewErrUnexpectedEOF(r, ctx.Location)
+	}
+
+	c, size := utf8.DecodeRune(input)
+	if !r.Match(c) || (c == utf8.RuneError && size <= 1) {
+		return nil, NewErrUnexpectedToken(r, ctx.Location, ShowInput(input))
+	}
+
+	line, col := ctx.Parser.Locate(ctx.Location.Position)
+	tree := &Tree{
+		Rule: r,
+		Location: &Location{
+			Path:     ctx.Location.Path,
+			Position: ctx.Location.Position,
+			Line:     line,
+			Column:   col,
+		},
+		Region: &Region{
+			Start: ctx.Location.Position,
+			End:   ctx.Location.Position + size,
+		},
+		Depth: ctx.Depth,
+		Data:  input[:size],
+	}
+	if err := runHooks(ctx, r, tree, r.Hooks); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// Match returns true if rune c belongs to this class.
+func (r *RuneClass) Match(c rune) bool {
+	return unicode.IsOneOf(r.Tables, c) != r.Negate
+}
+
+// Class returns a human readable representation of the Tables
+// in a regexp-like form, well-known unicode tables are
+// represented by their names, like `\p{L}`.
+func (r *RuneClass) Class() string {
+	var (
+		buf    = &strings.Builder{}
+		negate = ""
+	)
+	if r.Negate {
+		negate = "^"
+	}
+	fmt.Fprintf(buf, "[%s", negate)
+	for _, table := range r.Tables {
+		if name := runeClassTableName(table); name != "" {
+			fmt.Fprintf(buf, `\p{%s}`, name)
+			continue
+		}
+		for _, rng := range table.R16 {
+			runeClassWriteRange(buf, rune(rng.Lo), rune(rng.Hi), rune(rng.Stride))
+		}
+		for _, rng := range table.R32 {
+			runeClassWriteRange(buf, rune(rng.Lo), rune(rng.Hi), rune(rng.Stride))
+		}
+	}
+	buf.WriteString("]")
+	return buf.String()
+}
+
+// runeClassTableName finds a name of the well-known unicode table,
+// returns empty string if table is not known.
+func runeClassTableName(table *unicode.RangeTable) string {
+	for _, tables := range []map[string]*unicode.RangeTable{
+		unicode.Categories,
+		unicode.Scripts,
+		unicode.Properties,
+	} {
+		for name, v := range tables {
+			if v == table {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// runeClassWriteRange writes a range of runes into buf.
+func runeClassWriteRange(buf *strings.Builder, lo rune, hi rune, stride rune) {
+	switch {
+	case lo == hi:
+		buf.WriteString(runeClassQuote(lo))
+	case stride == 1 && hi-lo > 1:
+		buf.WriteString(runeClassQuote(lo) + "-" + runeClassQuote(hi))
+	default:
+		for c := lo; c <= hi; c += stride {
+			buf.WriteString(runeClassQuote(c))
+		}
+	}
+}
+
+// runeClassQuote quotes rune c to use inside a class.
+func runeClassQuote(c rune) string {
+	switch {
+	case strings.ContainsRune(`\-[]^`, c):
+		return `\` + string(c)
+	case unicode.IsPrint(c):
+		return string(c)
+	default:
+		return strings.Trim(fmt.Sprintf("%+q", c), "'")
+	}
+}
+
+//
+
+// NewRuneClass constructs new *RuneClass which
+// matches a rune from any of the specified tables.
+func NewRuneClass(name string, tables []*unicode.RangeTable, hooks ...RuleParseHook) *RuneClass {
+	return &RuneClass{
+		name:   name,
+		Tables: tables,
+		Hooks:  hooks,
+	}
+}
+
+// NewRuneClassNegated constructs new *RuneClass which
+// matches a rune not found in any of the specified tables.
+func NewRuneClassNegated(name string, tables []*unicode.RangeTable, hooks ...RuleParseHook) *RuneClass {
+	return &RuneClass{
+		name:   name,
+		Tables: tables,
+		Negate: true,
+		Hooks:  hooks,
+	}
+}
+
+// NewRuneRange constructs new *RuneClass which
+// matches a rune in a range [from, to] inclusive.
+func NewRuneRange(name string, from rune, to rune, hooks ...RuleParseHook) *RuneClass {
+	if from > to {
+		panic(fmt.Errorf(
+			"invalid range, `from` (%q) should be less than `to` (%q)",
+			from, to,
+		))
+	}
+	return NewRuneClass(
+		name,
+		[]*unicode.RangeTable{NewRangeTable([2]rune{from, to})},
+		hooks...,
+	)
+}
+
+// NewRuneSet constructs new *RuneClass which
+// matches any rune from the runes string.
+func NewRuneSet(name string, runes string, hooks ...RuleParseHook) *RuneClass {
+	return NewRuneClass(
+		name,
+		[]*unicode.RangeTable{NewRangeTableRunes(runes)},
+		hooks...,
+	)
+}
+
+// NewRuneSetNegated constructs new *RuneClass which
+// matches any rune not in the runes string.
+func NewRuneSetNegated(name string, runes string, hooks ...RuleParseHook) *RuneClass {
+	return NewRuneClassNegated(
+		name,
+		[]*unicode.RangeTable{NewRangeTableRunes(runes)},
+		hooks...,
+	)
+}
+
+// NewUnicodeClass constructs new *RuneClass which matches
+// a rune from unicode table, like unicode.L or unicode.Nd.
+func NewUnicodeClass(name string, table *unicode.RangeTable, hooks ...RuleParseHook) *RuneClass {
+	return NewRuneClass(name, []*unicode.RangeTable{table}, hooks...)
+}
+
+// NewUnicodeClassNegated constructs new *RuneClass which matches
+// a rune not in the unicode table.
+func NewUnicodeClassNegated(name string, table *unicode.RangeTable, hooks ...RuleParseHook) *RuneClass {
+	return NewRuneClassNegated(name, []*unicode.RangeTable{table}, hooks...)
+}
+
+//
+
+// NewRangeTable constructs *unicode.RangeTable from
+// a list of inclusive [from, to] rune ranges.
+// Ranges are sorted and merged if they overlap.
+func NewRangeTable(ranges ...[2]rune) *unicode.RangeTable {
+	sorted := make([][2]rune, len(ranges))
+	copy(sorted, ranges)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i][0] < sorted[j][0]
+	})
+
+	merged := make([][2]rune, 0, len(sorted))
+	for _, rng := range sorted {
+		n := len(merged) - 1
+		if n >= 0 && rng[0] <= merged[n][1]+1 {
+			if rng[1] > merged[n][1] {
+				merged[n][1] = rng[1]
+			}
+			continue
+		}
+		merged = append(merged, rng)
+	}
+
+	table := &unicode.RangeTable{}
+	for _, rng := range merged {
+		lo, hi := rng[0], rng[1]
+		if lo <= unicode.MaxLatin1 {
+			table.LatinOffset++
+		}
+		if lo <= 0xffff {
+			top := hi
+			if top > 0xffff {
+				top = 0xffff
+			}
+			table.R16 = append(table.R16, unicode.Range16{
+				Lo:     uint16(lo),
+				Hi:     uint16(top),
+				Stride: 1,
+			})
+			if hi <= 0xffff {
+				continue
+			}
+			lo = 0x10000
+		}
+		table.R32 = append(table.R32, unicode.Range32{
+			Lo:     uint32(lo),
+			Hi:     uint32(hi),
+			Stride: 1,
+		})
+	}
+	if table.LatinOffset > 0 && table.R16[table.LatinOffset-1].Hi > unicode.MaxLatin1 {
+		table.LatinOffset--
+	}
+	return table
+}
+
+// NewRangeTableRunes constructs *unicode.RangeTable
+// which contains every rune from the runes string.
+func NewRangeTableRunes(runes string) *unicode.RangeTable {
+	ranges := make([][2]rune, 0, len(runes))
+	for _, c := range runes {
+		ranges = append(ranges, [2]rune{c, c})
+	}
+	return NewRangeTable(ranges...)
+}
@@ -0,0 +1,171 @@
+package parse
+
+import (
+	"fmt"
+	"testing"
+	"unicode"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuneClassShow(t *testing.T) {
+	samples := []struct {
+		rule Rule
+		show string
+	}{
+		{
+			NewRuneRange("digit", '0', '9'),
+			"*parse.RuneClass(class: [0-9], name: digit, negate: false)(none)",
+		},
+		{
+			NewRuneSet("sign", "+-*/"),
+			`*parse.RuneClass(class: [*+\-/], name: sign, negate: false)(none)`,
+		},
+		{
+			NewUnicodeClass("letter", unicode.L),
+			`*parse.RuneClass(class: [\p{L}], name: letter, negate: false)(none)`,
+		},
+		{
+			NewUnicodeClassNegated("not cyrillic", unicode.Cyrillic),
+			`*parse.RuneClass(class: [^\p{Cyrillic}], name: not cyrillic, negate: true)(none)`,
+		},
+		{
+			NewRuneClass(
+				"identifier",
+				[]*unicode.RangeTable{unicode.L, NewRangeTableRunes("_\t")},
+			),
+			`*parse.RuneClass(class: [\p{L}\t_], name: identifier, negate: false)(none)`,
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample)
+			assert.EqualValues(t, sample.show, sample.rule.Show("none"), msg)
+			assert.Nil(t, sample.rule.GetChilds(), msg)
+			assert.True(t, sample.rule.IsFinite(), msg)
+		})
+	}
+}
+
+func TestRuneClass(t *testing.T) {
+	digit := NewRuneRange("digit", '0', '9')
+	emoji := NewRuneRange("emoji", 0x1F600, 0x1F64F)
+	notQuote := NewRuneSetNegated("not quote", `"`)
+	letter := NewUnicodeClass("letter", unicode.L)
+	identifier := NewChain(
+		"identifier",
+		letter,
+		NewRepetitionTimesVariadic(
+			"tail",
+			0,
+			NewRuneClass(
+				"letter or digit",
+				[]*unicode.RangeTable{unicode.L, unicode.Nd},
+			),
+		),
+	)
+
+	samples := []struct {
+		text string
+		rule Rule
+		data []byte
+		err  error
+	}{
+		{"5", digit, []byte("5"), nil},
+		{"😀", emoji, []byte("😀"), nil},
+		{"ж", notQuote, []byte("ж"), nil},
+		{"字", letter, []byte("字"), nil},
+		{"переменная1", identifier, []byte("переменная1"), nil},
+		{"変数名", identifier, []byte("変数名"), nil},
+		{
+			"a",
+			digit,
+			nil,
+			NewErrUnexpectedToken(
+				digit,
+				&Location{Path: DefaultParserPath},
+				[]byte("a"),
+			),
+		},
+		{
+			`"`,
+			notQuote,
+			nil,
+			NewErrUnexpectedToken(
+				notQuote,
+				&Location{Path: DefaultParserPath},
+				[]byte(`"`),
+			),
+		},
+		{
+			"\xff",
+			NewUnicodeClassNegated("not letter", unicode.L),
+			nil,
+			NewErrUnexpectedToken(
+				NewUnicodeClassNegated("not letter", unicode.L),
+				&Location{Path: DefaultParserPath},
+				[]byte("\xff"),
+			),
+		},
+		{
+			"",
+			letter,
+			nil,
+			NewErrUnexpectedEOF(letter, &Location{Path: DefaultParserPath}),
+		},
+		{
+			"1переменная",
+			identifier,
+			nil,
+			NewErrUnexpectedToken(
+				letter,
+				&Location{Path: DefaultParserPath},
+				[]byte("1переменная"),
+			),
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample.text)
+			tree, err := Parse(sample.rule, []byte(sample.text))
+			assert.EqualValues(t, sample.err, err, msg)
+			if sample.err != nil {
+				return
+			}
+			assert.Equal(t, sample.data, tree.Data, msg)
+			assert.Equal(t, len(sample.data), tree.Region.End, msg)
+		})
+	}
+}
+
+func TestNewRangeTable(t *testing.T) {
+	samples := []struct {
+		ranges [][2]rune
+		in     []rune
+		out    []rune
+	}{
+		{
+			[][2]rune{{'a', 'c'}, {'b', 'f'}, {'x', 'x'}},
+			[]rune{'a', 'd', 'f', 'x'},
+			[]rune{'g', 'w', 'y'},
+		},
+		{
+			[][2]rune{{0xF0, 0x10010}},
+			[]rune{0xF0, 'ж', 0xFFFF, 0x10000, 0x10010},
+			[]rune{0xEF, 0x10011},
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample)
+			table := NewRangeTable(sample.ranges...)
+			for _, c := range sample.in {
+				assert.True(t, unicode.Is(table, c), fmt.Sprintf("%q %s", c, msg))
+			}
+			for _, c := range sample.out {
+				assert.False(t, unicode.Is(table, c), fmt.Sprintf("%q %s", c, msg))
+			}
+		})
+	}
+}
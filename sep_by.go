@@ -0,0 +1,308 @@
+package parse
+
+import (
+	"fmt"
+)
+
+var _ Rule = new(SepBy)
+
+var (
+	ErrSepByTrailingForbidden = fmt.Errorf("trailing separator is not allowed")
+	ErrSepByTrailingRequired  = fmt.Errorf("trailing separator is required")
+)
+
+// SepByTrailing is a policy of the trailing separator for SepBy.
+type SepByTrailing int
+
+const (
+	// SepByTrailingForbid makes trailing separator an error.
+	SepByTrailingForbid SepByTrailing = iota
+	// SepByTrailingAllow permits optional trailing separator.
+	SepByTrailingAllow
+	// SepByTrailingRequire makes every item followed by separator.
+	SepByTrailingRequire
+)
+
+func (t SepByTrailing) String() string {
+	switch t {
+	case SepByTrailingForbid:
+		return "forbid"
+	case SepByTrailingAllow:
+		return "allow"
+	case SepByTrailingRequire:
+		return "require"
+	default:
+		return fmt.Sprintf("SepByTrailing(%d)", int(t))
+	}
+}
+
+// SepBy is a Rule which matches a list of Rule
+// separated by Separator, like `item (sep item)*`.
+// List should contain at least Min items, empty list
+// yields a Tree with empty Region & Data and no childs.
+// Trailing separator is handled according to Trailing policy.
+//
+// Tree.Childs contains only items unless Separators
+// is set, in this case separators are interleaved with items.
+type SepBy struct {
+	name       string
+	Rule       Rule
+	Separator  Rule
+	Min        int
+	Trailing   SepByTrailing
+	Separators bool
+	Hooks      []RuleParseHook
+	EnterHooks []RuleEnterHook
+}
+
+// Name indicates the name which was given to the rule
+// on creation. Name could be not unique.
+func (r *SepBy) Name() string {
+	return r.name
+}
+
+// Show this node as a string.
+// You should provide childs as string
+// to this function, it does not care
+// about nesting in a tree, it only shows
+// string representation of itself.
+func (r *SepBy) Show(childs string) string {
+	return RuleShow(
+		r,
+		r.GetParameters().String(),
+		childs,
+	)
+}
+
+// String returns rule as a string,
+// resolving recursion with `<circular>` placeholder.
+func (r *SepBy) String() string {
+	return TreerString(r)
+}
+
+// GetChilds returns a slice of Rule which is
+// children for current Rule.
+func (r *SepBy) GetChilds() Treers {
+	return Treers{r.Rule, r.Separator}
+}
+
+//
+
+// GetParameters returns a KV rule parameters.
+func (r *SepBy) GetParameters() RuleParameters {
+	return RuleParameters{
+		"name":       r.name,
+		"min":        r.Min,
+		"trailing":   r.Trailing,
+		"separators": r.Separators,
+	}
+}
+
+// IsFinite returns true if this rule is
+// not a wrapper for other rules.
+func (r *SepBy) IsFinite() bool {
+	return false
+}
+
+// Parse consumes some bytes from input & emits a Tree
+// using settings defined during creation of the concrete Rule type.
+// May return an error if something goes wrong, should provide some
+// location information to the user which points to position in input.
+func (r *SepBy) Parse(ctx *Context, input []byte) (*Tree, error) {
+	if err := runEnterHooks(ctx, r, input, r.EnterHooks); err != nil {
+		return nil, err
+	}
+
+	if r.Rule == nil || r.Separator == nil {
+		return nil, NewErrEmptyRule(r, ctx.Rule)
+	}
+
+	nextDepth := ctx.Depth + 1
+	if nextDepth > ctx.Parser.MaxDepth {
+		return nil, NewErrNestingTooDeep(ctx.Location, nextDepth)
+	}
+
+	var (
+		start     = ctx.Location.Position
+		pos       = start
+		items     = 0
+		separated = false
+		subTree   *Tree
+		subChilds = []*Tree{}
+		err       error
+	)
+	for {
+		subTree, err = r.parse(ctx, r.Rule, pos, input)
+		if err != nil {
+			if !isErrNoMatch(err) {
+				return nil, err
+			}
+			if separated && r.Trailing == SepByTrailingForbid {
+				return nil, r.error(ctx, pos, input, ErrSepByTrailingForbidden, err)
+			}
+			break
+		}
+		items++
+		subChilds = append(subChilds, subTree)
+		separated = false
+		itemPos := pos
+		pos = subTree.Region.End
+
+		subTree, err = r.parse(ctx, r.Separator, pos, input)
+		if err != nil {
+			if !isErrNoMatch(err) {
+				return nil, err
+			}
+			if r.Trailing == SepByTrailingRequire {
+				return nil, r.error(ctx, pos, input, ErrSepByTrailingRequired, err)
+			}
+			break
+		}
+		if r.Separators {
+			subChilds = append(subChilds, subTree)
+		}
+		separated = true
+		pos = subTree.Region.End
+
+		if pos == itemPos { // NOTE: nothing consumed, prevent infinite loop
+			break
+		}
+	}
+	if items < r.Min {
+		return nil, r.error(
+			ctx, pos, input,
+			NewErrRepetitionNotEnoughOccurrences(r.Min, items),
+			err,
+		)
+	}
+
+	line, col := ctx.Parser.Locate(start)
+	tree := &Tree{
+		Rule: r,
+		Location: &Location{
+			Path:     ctx.Location.Path,
+			Position: start,
+			Line:     line,
+			Column:   col,
+		},
+		Region: &Region{
+			Start: start,
+			End:   pos,
+		},
+		Depth:  ctx.Depth,
+		Childs: subChilds,
+		Data:   input[:pos-start],
+	}
+	if err := runHooks(ctx, r, tree, r.Hooks); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// parse applies sub-rule at the position pos,
+// state is rolled back if sub-rule fails.
+func (r *SepBy) parse(ctx *Context, rule Rule, pos int, input []byte) (*Tree, error) {
+	line, col := ctx.Parser.Locate(pos)
+	snapshot := ctx.snapshot()
+	parse := func() (*Tree, error) {
+		return ctx.Parser.ParseRule(
+			rule,
+			&Context{
+				Rule:   r,
+				Parser: ctx.Parser,
+				Location: &Location{
+					Path:     ctx.Location.Path,
+					Position: pos,
+					Line:     line,
+					Column:   col,
+				},
+				Depth: ctx.Depth + 1,
+				State: ctx.State,
+			},
+			input[pos-ctx.Location.Position:],
+		)
+	}
+	var (
+		tree *Tree
+		err  error
+	)
+	if ctx.Parser.recovering() {
+		// NOTE: error recovery is a last resort, the rule
+		// is tried without it first, so list could end
+		// where the rule does not match
+		ctx.Parser.noRecover++
+		tree, err = parse()
+		ctx.Parser.noRecover--
+		if err != nil && err != ErrSkipRule && isErrNoMatch(err) {
+			snapshot.restore()
+			tree, err = parse()
+		}
+	} else {
+		tree, err = parse()
+	}
+	if err != nil {
+		snapshot.restore()
+	}
+	return tree, err
+}
+
+// error constructs ErrUnexpectedToken at the position pos
+// with reason and optional cause of the failure.
+func (r *SepBy) error(ctx *Context, pos int, input []byte, reason error, cause error) error {
+	line, col := ctx.Parser.Locate(pos)
+	inner := []error{reason}
+	if cause != nil && cause != ErrSkipRule {
+		inner = append(inner, cause)
+	}
+	return NewErrUnexpectedToken(
+		r,
+		&Location{
+			Path:     ctx.Location.Path,
+			Position: pos,
+			Line:     line,
+			Column:   col,
+		},
+		ShowInput(input[pos-ctx.Location.Position:]),
+		inner...,
+	)
+}
+
+//
+
+// NewSepBy constructs new *SepBy which matches
+// zero or more rule occurrences separated by separator.
+func NewSepBy(name string, rule Rule, separator Rule, hooks ...RuleParseHook) *SepBy {
+	return &SepBy{
+		name:      name,
+		Rule:      rule,
+		Separator: separator,
+		Hooks:     hooks,
+	}
+}
+
+// NewSepBy1 constructs new *SepBy which matches
+// one or more rule occurrences separated by separator.
+func NewSepBy1(name string, rule Rule, separator Rule, hooks ...RuleParseHook) *SepBy {
+	return &SepBy{
+		name:      name,
+		Rule:      rule,
+		Separator: separator,
+		Min:       1,
+		Hooks:     hooks,
+	}
+}
+
+//
+
+// isErrNoMatch returns true if err means the rule
+// is not matched and other alternatives could be tried.
+func isErrNoMatch(err error) bool {
+	if err == ErrSkipRule {
+		return true
+	}
+	switch err.(type) {
+	case *ErrUnexpectedToken, *ErrUnexpectedEOF:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package parse
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSepByShow(t *testing.T) {
+	samples := []struct {
+		rule   Rule
+		show   string
+		childs Treers
+	}{
+		{
+			NewSepBy("args", NewTerminal("x", "x"), NewTerminal(",", ",")),
+			"*parse.SepBy(min: 0, name: args, separators: false, trailing: forbid)(none)",
+			Treers{NewTerminal("x", "x"), NewTerminal(",", ",")},
+		},
+		{
+			NewSepBy1("args", NewTerminal("x", "x"), NewTerminal(",", ",")),
+			"*parse.SepBy(min: 1, name: args, separators: false, trailing: forbid)(none)",
+			Treers{NewTerminal("x", "x"), NewTerminal(",", ",")},
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample)
+			assert.EqualValues(t, sample.show, sample.rule.Show("none"), msg)
+			assert.EqualValues(t, sample.childs, sample.rule.GetChilds(), msg)
+			assert.False(t, sample.rule.IsFinite(), msg)
+		})
+	}
+}
+
+func TestSepBy(t *testing.T) {
+	var (
+		number = NewRegexp("number", "^[0-9]+")
+		comma  = NewTerminal(",", ",")
+		semi   = NewTerminal(";", ";")
+
+		args       = NewSepBy("args", number, comma)
+		args1      = NewSepBy1("args", number, comma)
+		args2      = NewSepBy1("args", number, comma)
+		trailing   = NewSepBy("args", number, comma)
+		statements = NewSepBy("statements", number, semi)
+		separators = NewSepBy("args", number, comma)
+
+		call = NewChain(
+			"call",
+			NewTerminal("(", "("),
+			args,
+			NewTerminal(")", ")"),
+		)
+	)
+	args2.Min = 2
+	trailing.Trailing = SepByTrailingAllow
+	statements.Trailing = SepByTrailingRequire
+	separators.Separators = true
+
+	samples := []struct {
+		text string
+		rule Rule
+		data []string
+		err  error
+	}{
+		{"1", args, []string{"1"}, nil},
+		{"1,22,333", args, []string{"1", "22", "333"}, nil},
+		{"", args, []string{}, nil},
+		{"()", call, []string{"(", "", ")"}, nil},
+		{"(1,2)", call, []string{"(", "1,2", ")"}, nil},
+		{"1,2", args2, []string{"1", "2"}, nil},
+		{"1,2,", trailing, []string{"1", "2"}, nil},
+		{"1,2", trailing, []string{"1", "2"}, nil},
+		{"1;2;", statements, []string{"1", "2"}, nil},
+		{"", statements, []string{}, nil},
+		{"1,2", separators, []string{"1", ",", "2"}, nil},
+		{
+			"1,2,",
+			args,
+			nil,
+			NewErrUnexpectedToken(
+				args,
+				&Location{Path: DefaultParserPath, Position: 4, Column: 3},
+				[]byte{},
+				ErrSepByTrailingForbidden,
+				NewErrUnexpectedToken(
+					number,
+					&Location{Path: DefaultParserPath, Position: 4, Column: 3},
+					ShowInput([]byte{}),
+				),
+			),
+		},
+		{
+			"1;2",
+			statements,
+			nil,
+			NewErrUnexpectedToken(
+				statements,
+				&Location{Path: DefaultParserPath, Position: 3, Column: 2},
+				[]byte{},
+				ErrSepByTrailingRequired,
+				NewErrUnexpectedEOF(semi, &Location{Path: DefaultParserPath, Position: 3, Column: 2}),
+			),
+		},
+		{
+			"",
+			args1,
+			nil,
+			NewErrUnexpectedToken(
+				args1,
+				&Location{Path: DefaultParserPath},
+				[]byte{},
+				NewErrRepetitionNotEnoughOccurrences(1, 0),
+				NewErrUnexpectedToken(
+					number,
+					&Location{Path: DefaultParserPath},
+					ShowInput([]byte{}),
+				),
+			),
+		},
+		{
+			"1",
+			args2,
+			nil,
+			NewErrUnexpectedToken(
+				args2,
+				&Location{Path: DefaultParserPath, Position: 1},
+				[]byte{},
+				NewErrRepetitionNotEnoughOccurrences(2, 1),
+				NewErrUnexpectedEOF(comma, &Location{Path: DefaultParserPath, Position: 1}),
+			),
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample.text)
+			tree, err := Parse(sample.rule, []byte(sample.text))
+			assert.EqualValues(t, sample.err, err, msg)
+			if sample.err != nil {
+				return
+			}
+
+			data := make([]string, len(tree.Childs))
+			for n, child := range tree.Childs {
+				data[n] = string(child.Data)
+			}
+			assert.Equal(t, sample.data, data, msg)
+			assert.Equal(t, []byte(sample.text), tree.Data, msg)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package parse
+
+// Transactional is implemented by Context.State values
+// which should be rolled back when Parser backtracks.
+// Snapshot returns an opaque value describing current state,
+// Restore rolls the state back to the snapshot.
+// Parser restores snapshots in reverse order of taking them,
+// restored snapshot could be restored again later.
+//
+// State is rolled back when:
+//
+//   - Either alternative fails,
+//   - Repetition or SepBy occurrence fails,
+//   - Optional rule is absent,
+//   - Expression operator fails,
+//   - lookahead predicate (Not, And) finishes,
+//   - left-recursive application does not grow the seed,
+//   - input is skipped by the error recovery.
+//
+// Either with Longest set rolls back every alternative
+// and applies the winner again.
+// Memoized results are reused without calling hooks,
+// so ParserOptionMemoize has no effect when state is Transactional.
+type Transactional interface {
+	Snapshot() interface{}
+	Restore(snapshot interface{})
+}
+
+// contextSnapshot is a snapshot of the Transactional Context.State.
+type contextSnapshot struct {
+	state    Transactional
+	snapshot interface{}
+}
+
+// snapshot takes a snapshot of Context.State,
+// it returns nil if state is not Transactional.
+func (c *Context) snapshot() *contextSnapshot {
+	state, ok := c.State.(Transactional)
+	if !ok {
+		return nil
+	}
+	return &contextSnapshot{state, state.Snapshot()}
+}
+
+// restore rolls Context.State back to the snapshot.
+func (s *contextSnapshot) restore() {
+	if s != nil {
+		s.state.Restore(s.snapshot)
+	}
+}
+
+//
+
+// StateMap is a Transactional map which could be used as
+// Context.State (or as a part of it) to keep symbol tables.
+// Changes are journaled, so taking a snapshot is cheap and
+// rollback costs proportionally to the number of changes reverted.
+type StateMap[K comparable, V any] struct {
+	values  map[K]V
+	journal []stateMapChange[K, V]
+}
+
+// stateMapChange is a journaled StateMap change
+// holding a value which was replaced.
+type stateMapChange[K comparable, V any] struct {
+	key     K
+	value   V
+	existed bool
+}
+
+// Get returns a value stored with the key.
+func (m *StateMap[K, V]) Get(key K) (V, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Has returns true if value with the key is stored.
+func (m *StateMap[K, V]) Has(key K) bool {
+	_, ok := m.values[key]
+	return ok
+}
+
+// Set stores the value with the key.
+func (m *StateMap[K, V]) Set(key K, value V) {
+	m.journal = append(m.journal, m.change(key))
+	m.values[key] = value
+}
+
+// Delete removes the value stored with the key.
+func (m *StateMap[K, V]) Delete(key K) {
+	if !m.Has(key) {
+		return
+	}
+	m.journal = append(m.journal, m.change(key))
+	delete(m.values, key)
+}
+
+// Len returns number of stored values.
+func (m *StateMap[K, V]) Len() int {
+	return len(m.values)
+}
+
+// Map returns a copy of stored values.
+func (m *StateMap[K, V]) Map() map[K]V {
+	values := make(map[K]V, len(m.values))
+	for k, v := range m.values {
+		values[k] = v
+	}
+	return values
+}
+
+// Snapshot implements Transactional.
+func (m *StateMap[K, V]) Snapshot() interface{} {
+	return len(m.journal)
+}
+
+// Restore implements Transactional.
+func (m *StateMap[K, V]) Restore(snapshot interface{}) {
+	n := snapshot.(int)
+	for len(m.journal) > n {
+		change := m.journal[len(m.journal)-1]
+		m.journal = m.journal[:len(m.journal)-1]
+		if change.existed {
+			m.values[change.key] = change.value
+		} else {
+			delete(m.values, change.key)
+		}
+	}
+}
+
+// change returns a journal entry to revert a change of the key.
+func (m *StateMap[K, V]) change(key K) stateMapChange[K, V] {
+	value, existed := m.values[key]
+	return stateMapChange[K, V]{key, value, existed}
+}
+
+// NewStateMap constructs new empty *StateMap.
+func NewStateMap[K comparable, V any]() *StateMap[K, V] {
+	return &StateMap[K, V]{values: map[K]V{}}
+}
@@ -0,0 +1,146 @@
+package parse
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateMap(t *testing.T) {
+	m := NewStateMap[string, int]()
+	m.Set("a", 1)
+	s1 := m.Snapshot()
+
+	m.Set("a", 2)
+	m.Set("b", 3)
+	m.Delete("missing")
+	s2 := m.Snapshot()
+
+	m.Delete("a")
+	value, ok := m.Get("b")
+	assert.Equal(t, 3, value)
+	assert.True(t, ok)
+	assert.False(t, m.Has("a"))
+	assert.Equal(t, 1, m.Len())
+
+	m.Restore(s2)
+	assert.Equal(t, map[string]int{"a": 2, "b": 3}, m.Map())
+
+	m.Restore(s1)
+	assert.Equal(t, map[string]int{"a": 1}, m.Map())
+
+	m.Set("c", 4)
+	m.Restore(s1)
+	assert.Equal(t, map[string]int{"a": 1}, m.Map())
+
+	var _ Transactional = m
+}
+
+func TestParserStateRollback(t *testing.T) {
+	var (
+		declare = func(ctx *Context, tree *Tree) error {
+			ctx.State.(*StateMap[string, bool]).Set(string(tree.Data), true)
+			return nil
+		}
+		name      = NewRegexp("name", "^[a-z]+", declare)
+		number    = NewRegexp("number", "^[0-9]+")
+		semicolon = NewTerminal(";", ";")
+		// declaration registers the name before it fails on `=`
+		declaration = NewChain("declaration", name, semicolon)
+		assignment  = NewChain("assignment", NewRegexp("target", "^[a-z]+"), NewTerminal("=", "="), number, semicolon)
+		statement   = NewEither("statement", declaration, assignment)
+		list        = NewSepBy("list", declaration, NewTerminal(",", ","))
+	)
+	list.Trailing = SepByTrailingAllow
+
+	samples := []struct {
+		text     string
+		rule     Rule
+		declared []string
+	}{
+		{"a;", statement, []string{"a"}},
+		{"a=1;", statement, []string{}},
+		{"a;b=1;c;", NewRepetition("statements", statement), []string{"a", "c"}},
+		{
+			"a;b",
+			NewChain("chain", NewRepetition("declarations", declaration), NewRegexp("tail", "^[a-z]+")),
+			[]string{"a"},
+		},
+		{"a", NewChain("optional", NewOptional("declaration", declaration), NewRegexp("tail", "^[a-z]+")), []string{}},
+		{"a", NewChain("lookahead", NewAnd("and", name), NewNot("not", declaration), NewRegexp("tail", "^[a-z]+")), []string{}},
+		{"a;,b", NewChain("list", list, NewRegexp("tail", "^[a-z]+")), []string{"a"}},
+		{
+			"ab;",
+			NewEitherLongest(
+				"longest",
+				NewChain("short", NewRegexp("a", "^a", declare), NewRegexp("b", "^b")),
+				NewChain("long", NewRegexp("ab", "^ab", declare), semicolon),
+				NewChain("failed", NewRegexp("ab", "^ab", declare), NewTerminal("!", "!")),
+			),
+			[]string{"ab"},
+		},
+		{
+			"foo?",
+			NewEither(
+				"statement",
+				NewChain("exclamation", name, NewTerminal("!", "!")),
+				NewChain("question", name, NewTerminal("?", "?")),
+			),
+			[]string{"foo"},
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			for _, memoize := range []bool{false, true} {
+				msg := spew.Sdump(k, sample.text, memoize)
+				state := NewStateMap[string, bool]()
+				_, err := NewParser(
+					ParserOptionMemoize(memoize),
+				).ParseState(sample.rule, []byte(sample.text), state)
+				if err != nil {
+					t.Fatal(err)
+				}
+				declared := map[string]bool{}
+				for _, name := range sample.declared {
+					declared[name] = true
+				}
+				assert.Equal(t, declared, state.Map(), msg)
+			}
+		})
+	}
+}
+
+func TestParserStateLeftRecursion(t *testing.T) {
+	var (
+		term = NewRegexp("term", "^[0-9]", func(ctx *Context, tree *Tree) error {
+			ctx.State.(*StateMap[int, string]).Set(tree.Location.Position, string(tree.Data))
+			return nil
+		})
+		expr = NewEither("expr")
+	)
+	expr.Add(NewChain("add", expr, NewTerminal("+", "+"), term), term)
+
+	samples := []struct {
+		text  string
+		terms map[int]string
+	}{
+		{"1", map[int]string{0: "1"}},
+		{"1+2", map[int]string{0: "1", 2: "2"}},
+		{"1+2+3", map[int]string{0: "1", 2: "2", 4: "3"}},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample.text)
+			state := NewStateMap[int, string]()
+			tree, err := NewParser().ParseState(expr, []byte(sample.text), state)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, []byte(sample.text), tree.Data, msg)
+			assert.Equal(t, sample.terms, state.Map(), msg)
+			assert.Len(t, state.journal, len(sample.terms), msg)
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package parse
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var _ Rule = new(StringLiteral)
+
+var (
+	ErrStringLiteralLineBreak     = fmt.Errorf("line-break inside single-line string literal")
+	ErrStringLiteralInvalidEscape = fmt.Errorf("invalid escape sequence")
+)
+
+// StringLiteralEscape is a set of escape sequences
+// supported by StringLiteral.
+type StringLiteralEscape uint
+
+const (
+	// StringLiteralEscapeSimple enables \a \b \f \n \r \t \v.
+	StringLiteralEscapeSimple StringLiteralEscape = 1 << iota
+	// StringLiteralEscapeHex enables \xNN byte escapes.
+	StringLiteralEscapeHex
+	// StringLiteralEscapeUnicode enables \uXXXX and \UXXXXXXXX rune escapes.
+	StringLiteralEscapeUnicode
+	// StringLiteralEscapeOctal enables \N, \NN and \NNN byte escapes.
+	StringLiteralEscapeOctal
+
+	// StringLiteralEscapeAll enables every supported escape sequence.
+	StringLiteralEscapeAll = StringLiteralEscapeSimple |
+		StringLiteralEscapeHex |
+		StringLiteralEscapeUnicode |
+		StringLiteralEscapeOctal
+)
+
+// StringLiteral is a Rule which matches a quoted string
+// literal in input, decoding escape sequences.
+// Literal may start with any of the Quotes characters
+// and should end with the same character.
+// Escape character followed by a quote or by itself
+// is always decoded as the following character, other escape sequences
+// should be enabled with Escapes. Zero Escape disables escaping (raw literal).
+//
+// Tree.Data contains the literal with quotes as it was found in the input,
+// Tree.Value contains decoded string.
+type StringLiteral struct {
+	name       string
+	Quotes     string
+	Escape     rune
+	Escapes    StringLiteralEscape
+	Multiline  bool
+	Hooks      []RuleParseHook
+	EnterHooks []RuleEnterHook
+}
+
+// Name indicates the name which was given to the rule
+// on creation. Name could be not unique.
+func (r *StringLiteral) Name() string {
+	return r.name
+}
+
+// Show this node as a string.
+// You should provide childs as string
+// to this function, it does not care
+// about nesting in a tree, it only shows
+// string representation of itself.
+func (r *StringLiteral) Show(childs string) string {
+	return RuleShow(
+		r,
+		r.GetParameters().String(),
+		childs,
+	)
+}
+
+// String returns rule as a string,
+// resolving recursion with `<circular>` placeholder.
+func (r *StringLiteral) String() string {
+	return TreerString(r)
+}
+
+// GetChilds returns a slice of Rule which is
+// children for current Rule.
+func (r *StringLiteral) GetChilds() Treers {
+	return nil
+}
+
+//
+
+// GetParameters returns a KV rule parameters.
+func (r *StringLiteral) GetParameters() RuleParameters {
+	return RuleParameters{
+		"name":      r.name,
+		"quotes":    r.Quotes,
+		"escape":    string(r.Escape),
+		"escapes":   r.Escapes,
+		"multiline": r.Multiline,
+	}
+}
+
+// IsFinite returns true if this rule is
+// not a wrapper for other rules.
+func (r *StringLiteral) IsFinite() bool {
+	return true
+}
+
+// Parse consumes some bytes from input & emits a Tree
+// using settings defined during creation of the concrete Rule type.
+// May return an error if something goes wrong, should provide some
+// location information to the user which points to position in input.
+func (r *StringLiteral) Parse(ctx *Context, input []byte) (*Tree, error) {
+	if err := runEnterHooks(ctx, r, input, r.EnterHooks); err != nil {
+		return nil, err
+	}
+
+	if len(r.Quotes) == 0 {
+		return nil, NewErrEmptyRule(r, ctx.Rule)
+	}
+	if len(input) == 0 {
+		return nil, NewErrUnexpectedEOF(r, ctx.Location)
+	}
+
+	quote, n := utf8.DecodeRune(input)
+	if !strings.ContainsRune(r.Quotes, quote) {
+		return nil, NewErrUnexpectedToken(r, ctx.Location, ShowInput(input))
+	}
+
+	var (
+		value  = make([]byte, 0, len(input))
+		closed bool
+		c      rune
+		size   int
+		err    error
+	)
+	for n < len(input) {
+		c, size = utf8.DecodeRune(input[n:])
+		switch {
+		case c == quote:
+			closed = true
+		case c == '\n' && !r.Multiline:
+			return nil, NewErrUnexpectedToken(
+				r,
+				r.location(ctx, n),
+				ShowInput(input[n:]),
+				ErrStringLiteralLineBreak,
+			)
+		case c == r.Escape && r.Escape != 0:
+			value, size, err = r.unescape(value, input[n:])
+			if err != nil {
+				return nil, NewErrUnexpectedToken(
+					r,
+					r.location(ctx, n),
+					ShowInput(input[n:]),
+					err,
+				)
+			}
+		default:
+			value = append(value, input[n:n+size]...)
+		}
+		n += size
+		if closed {
+			break
+		}
+	}
+	if !closed {
+		return nil, NewErrBoundIncomplete(
+			input[:utf8.RuneLen(quote)],
+			input[:utf8.RuneLen(quote)],
+			r.location(ctx, 0),
+		)
+	}
+
+	line, col := ctx.Parser.Locate(ctx.Location.Position)
+	tree := &Tree{
+		Rule: r,
+		Location: &Location{
+			Path:     ctx.Location.Path,
+			Position: ctx.Location.Position,
+			Line:     line,
+			Column:   col,
+		},
+		Region: &Region{
+			Start: ctx.Location.Position,
+			End:   ctx.Location.Position + n,
+		},
+		Depth: ctx.Depth,
+		Data:  input[:n],
+		Value: string(value),
+	}
+	if err := runHooks(ctx, r, tree, r.Hooks); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// location returns a Location of the offset inside the literal.
+func (r *StringLiteral) location(ctx *Context, offset int) *Location {
+	pos := ctx.Location.Position + offset
+	line, col := ctx.Parser.Locate(pos)
+	return &Location{
+		Path:     ctx.Location.Path,
+		Position: pos,
+		Line:     line,
+		Column:   col,
+	}
+}
+
+// unescape decodes escape sequence at the start of the input
+// appending it to value, returns new value and length of the sequence.
+func (r *StringLiteral) unescape(value []byte, input []byte) ([]byte, int, error) {
+	n := utf8.RuneLen(r.Escape)
+	if n >= len(input) {
+		return value, n, ErrStringLiteralInvalidEscape
+	}
+
+	c, size := utf8.DecodeRune(input[n:])
+	n += size
+	switch {
+	case c == r.Escape || strings.ContainsRune(r.Quotes, c):
+		return utf8.AppendRune(value, c), n, nil
+	case r.Escapes&StringLiteralEscapeSimple != 0 && strings.ContainsRune("abfnrtv", c):
+		return append(value, "\a\b\f\n\r\t\v"[strings.IndexRune("abfnrtv", c)]), n, nil
+	case r.Escapes&StringLiteralEscapeHex != 0 && c == 'x':
+		v, digits := stringLiteralDigits(input[n:], 16, 2, 2)
+		if digits == 0 {
+			break
+		}
+		return append(value, byte(v)), n + digits, nil
+	case r.Escapes&StringLiteralEscapeUnicode != 0 && (c == 'u' || c == 'U'):
+		length := 4
+		if c == 'U' {
+			length = 8
+		}
+		v, digits := stringLiteralDigits(input[n:], 16, length, length)
+		if digits == 0 || !utf8.ValidRune(rune(v)) {
+			break
+		}
+		return utf8.AppendRune(value, rune(v)), n + digits, nil
+	case r.Escapes&StringLiteralEscapeOctal != 0 && c >= '0' && c <= '7':
+		v, digits := stringLiteralDigits(input[n-size:], 8, 1, 3)
+		if v > 0xff {
+			break
+		}
+		return append(value, byte(v)), n - size + digits, nil
+	}
+	return value, n, ErrStringLiteralInvalidEscape
+}
+
+// stringLiteralDigits parses from min to max digits with base
+// from the start of the input, returning value & number of digits.
+// Zero digits returned if there are less than min digits.
+func stringLiteralDigits(input []byte, base int, min int, max int) (int, int) {
+	var v, n int
+	for n < max && n < len(input) {
+		var digit int
+		c := input[n]
+		switch {
+		case c >= '0' && c <= '9':
+			digit = int(c - '0')
+		case c >= 'a' && c <= 'f':
+			digit = int(c-'a') + 10
+		case c >= 'A' && c <= 'F':
+			digit = int(c-'A') + 10
+		default:
+			digit = base
+		}
+		if digit >= base {
+			break
+		}
+		v = v*base + digit
+		n++
+	}
+	if n < min {
+		return 0, 0
+	}
+	return v, n
+}
+
+//
+
+// NewStringLiteral constructs new *StringLiteral
+// with specified quote characters, `\` as escape character
+// and all escape sequences enabled.
+func NewStringLiteral(name string, quotes string, hooks ...RuleParseHook) *StringLiteral {
+	return &StringLiteral{
+		name:    name,
+		Quotes:  quotes,
+		Escape:  '\\',
+		Escapes: StringLiteralEscapeAll,
+		Hooks:   hooks,
+	}
+}
+
+// NewRawStringLiteral constructs new multiline *StringLiteral
+// with specified quote characters and no escaping.
+func NewRawStringLiteral(name string, quotes string, hooks ...RuleParseHook) *StringLiteral {
+	return &StringLiteral{
+		name:      name,
+		Quotes:    quotes,
+		Multiline: true,
+		Hooks:     hooks,
+	}
+}
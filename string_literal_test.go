@@ -0,0 +1,182 @@
+package parse
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringLiteralShow(t *testing.T) {
+	samples := []struct {
+		rule Rule
+		show string
+	}{
+		{
+			NewStringLiteral("string", `"'`),
+			`*parse.StringLiteral(escape: \, escapes: 15, multiline: false, name: string, quotes: "')(none)`,
+		},
+		{
+			NewRawStringLiteral("raw", "`"),
+			"*parse.StringLiteral(escape: \x00, escapes: 0, multiline: true, name: raw, quotes: `)(none)",
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample)
+			assert.EqualValues(t, sample.show, sample.rule.Show("none"), msg)
+			assert.Nil(t, sample.rule.GetChilds(), msg)
+			assert.True(t, sample.rule.IsFinite(), msg)
+		})
+	}
+}
+
+func TestStringLiteral(t *testing.T) {
+	str := NewStringLiteral("string", `"'`)
+	raw := NewRawStringLiteral("raw", "`")
+	simple := NewStringLiteral("simple", `"`)
+	simple.Escapes = StringLiteralEscapeSimple
+
+	samples := []struct {
+		text  string
+		rule  Rule
+		data  string
+		value string
+		err   error
+	}{
+		{`"foo"`, str, `"foo"`, "foo", nil},
+		{`'foo'`, str, `'foo'`, "foo", nil},
+		{`"foo\"bar"`, str, `"foo\"bar"`, `foo"bar`, nil},
+		{`"it's"`, str, `"it's"`, "it's", nil},
+		{`"a\\b"`, str, `"a\\b"`, `a\b`, nil},
+		{`"\n\t\r\a\b\f\v"`, str, `"\n\t\r\a\b\f\v"`, "\n\t\r\a\b\f\v", nil},
+		{`"\x41Ж\U0001F600"`, str, `"\x41Ж\U0001F600"`, "AЖ\U0001F600", nil},
+		{`"\101\0\7a"`, str, `"\101\0\7a"`, "A\x00\x07a", nil},
+		{`"ж"`, str, `"ж"`, "ж", nil},
+		{`""`, str, `""`, "", nil},
+		{"`foo\\n\nbar`", raw, "`foo\\n\nbar`", "foo\\n\nbar", nil},
+		{`"\n\""`, simple, `"\n\""`, "\n\"", nil},
+		{
+			`foo`,
+			str,
+			"",
+			"",
+			NewErrUnexpectedToken(
+				str,
+				&Location{Path: DefaultParserPath},
+				[]byte("foo"),
+			),
+		},
+		{
+			``,
+			str,
+			"",
+			"",
+			NewErrUnexpectedEOF(str, &Location{Path: DefaultParserPath}),
+		},
+		{
+			`"foo`,
+			str,
+			"",
+			"",
+			NewErrBoundIncomplete(
+				[]byte(`"`),
+				[]byte(`"`),
+				&Location{Path: DefaultParserPath},
+			),
+		},
+		{
+			"\"foo\nbar\"",
+			str,
+			"",
+			"",
+			NewErrUnexpectedToken(
+				str,
+				&Location{
+					Path:     DefaultParserPath,
+					Position: 4,
+					Column:   4,
+				},
+				[]byte("..."),
+				ErrStringLiteralLineBreak,
+			),
+		},
+		{
+			`"a\qb"`,
+			str,
+			"",
+			"",
+			NewErrUnexpectedToken(
+				str,
+				&Location{
+					Path:     DefaultParserPath,
+					Position: 2,
+					Column:   2,
+				},
+				[]byte(`\qb"`),
+				ErrStringLiteralInvalidEscape,
+			),
+		},
+		{
+			`"\x4"`,
+			str,
+			"",
+			"",
+			NewErrUnexpectedToken(
+				str,
+				&Location{
+					Path:     DefaultParserPath,
+					Position: 1,
+					Column:   1,
+				},
+				[]byte(`\x4"`),
+				ErrStringLiteralInvalidEscape,
+			),
+		},
+		{
+			`"\x41"`,
+			simple,
+			"",
+			"",
+			NewErrUnexpectedToken(
+				simple,
+				&Location{
+					Path:     DefaultParserPath,
+					Position: 1,
+					Column:   1,
+				},
+				[]byte(`\x41"`),
+				ErrStringLiteralInvalidEscape,
+			),
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample.text)
+			tree, err := Parse(sample.rule, []byte(sample.text))
+			assert.EqualValues(t, sample.err, err, msg)
+			if sample.err != nil {
+				return
+			}
+			assert.Equal(t, sample.data, string(tree.Data), msg)
+			assert.Equal(t, sample.value, tree.Value, msg)
+		})
+	}
+}
+
+func TestStringLiteralChain(t *testing.T) {
+	rule := NewChain(
+		"assignment",
+		NewRegexp("key", "^[a-z]+"),
+		NewTerminal("=", "="),
+		NewStringLiteral("value", `"`),
+	)
+	tree, err := Parse(rule, []byte(`foo="bar\tbaz"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := tree.Childs[2]
+	assert.Equal(t, &Region{Start: 4, End: 14}, value.Region)
+	assert.Equal(t, "bar\tbaz", value.Value)
+}
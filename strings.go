@@ -2,6 +2,7 @@ package parse
 
 import (
 	"strings"
+	"unicode"
 )
 
 func indent(s string, character string, size int) string {
@@ -75,3 +76,22 @@ func EqualSlicesFoldSome(a []string, b ...[]string) bool {
 	}
 	return false
 }
+
+// EqualRuneFold checks that a and b are equal
+// under unicode simple case-folding.
+func EqualRuneFold(a, b rune) bool {
+	return FoldRune(a) == FoldRune(b)
+}
+
+// FoldRune returns canonical representation of the rune c
+// under unicode simple case-folding, which is the smallest
+// rune in the folding orbit of c.
+func FoldRune(c rune) rune {
+	min := c
+	for f := unicode.SimpleFold(c); f != c; f = unicode.SimpleFold(f) {
+		if f < min {
+			min = f
+		}
+	}
+	return min
+}
@@ -8,10 +8,14 @@ import (
 var _ Rule = new(Terminal)
 
 // Terminal is a Rule which is literal in input.
+// If Fold is set then literal is compared using unicode simple
+// case-folding, so `SELECT` matches `select` and `Select`.
 type Terminal struct {
-	name  string
-	Value []byte
-	Hooks []RuleParseHook
+	name       string
+	Value      []byte
+	Fold       bool
+	Hooks      []RuleParseHook
+	EnterHooks []RuleEnterHook
 }
 
 // Name indicates the name which was given to the rule
@@ -44,10 +48,14 @@ func (r *Terminal) GetChilds() Treers {
 
 // GetParameters returns a KV rule parameters.
 func (r *Terminal) GetParameters() RuleParameters {
-	return RuleParameters{
+	params := RuleParameters{
 		"name":  r.name,
 		"value": string(r.Value),
 	}
+	if r.Fold {
+		params["fold"] = r.Fold
+	}
+	return params
 }
 
 // IsFinite returns true if this rule is
@@ -61,19 +69,39 @@ func (r *Terminal) IsFinite() bool {
 // May return an error if something goes wrong, should provide some
 // location information to the user which points to position in input.
 func (r *Terminal) Parse(ctx *Context, input []byte) (*Tree, error) {
-	length := utf8.RuneCount(r.Value)
+	if err := runEnterHooks(ctx, r, input, r.EnterHooks); err != nil {
+		return nil, err
+	}
+
+	length := len(r.Value)
 	if length == 0 {
 		return nil, NewErrEmptyRule(r, ctx.Rule)
 	}
 
-	if utf8.RuneCount(input) < length {
-		return nil, NewErrUnexpectedEOF(r, ctx.Location)
+	if r.Fold {
+		var eof bool
+		length, eof = r.matchFold(input)
+		switch {
+		case eof:
+			return nil, NewErrUnexpectedEOF(r, ctx.Location)
+		case length == 0:
+			return nil, NewErrUnexpectedToken(r, ctx.Location, ShowInput(input))
+		}
+	} else {
+		if len(input) < length {
+			// NOTE: input which has less runes than the value is incomplete,
+			// otherwise it could not match
+			if utf8.RuneCount(input) < utf8.RuneCount(r.Value) {
+				return nil, NewErrUnexpectedEOF(r, ctx.Location)
+			}
+			return nil, NewErrUnexpectedToken(r, ctx.Location, ShowInput(input))
+		}
+		if !bytes.Equal(input[:length], r.Value) {
+			return nil, NewErrUnexpectedToken(r, ctx.Location, ShowInput(input))
+		}
 	}
 
 	buf := input[:length]
-	if !bytes.Equal(buf, r.Value) {
-		return nil, NewErrUnexpectedToken(r, ctx.Location, ShowInput(input))
-	}
 
 	line, col := ctx.Parser.Locate(ctx.Location.Position)
 	tree := &Tree{
@@ -91,12 +119,35 @@ func (r *Terminal) Parse(ctx *Context, input []byte) (*Tree, error) {
 		Depth: ctx.Depth,
 		Data:  buf,
 	}
-	for _, hook := range r.Hooks {
-		hook(ctx, tree)
+	if err := runHooks(ctx, r, tree, r.Hooks); err != nil {
+		return nil, err
 	}
 	return tree, nil
 }
 
+// matchFold matches Value with the input prefix using case-folding.
+// Returns length of the matched prefix in bytes (zero if not matched)
+// and true if input is a folded prefix of Value.
+func (r *Terminal) matchFold(input []byte) (int, bool) {
+	var (
+		value = r.Value
+		n     int
+	)
+	for len(value) > 0 {
+		if n == len(input) {
+			return 0, true
+		}
+		expected, expectedSize := utf8.DecodeRune(value)
+		c, size := utf8.DecodeRune(input[n:])
+		if !EqualRuneFold(c, expected) {
+			return 0, false
+		}
+		value = value[expectedSize:]
+		n += size
+	}
+	return n, false
+}
+
 //
 
 // NewTerminal constructs a new *Terminal.
@@ -107,3 +158,14 @@ func NewTerminal(name string, v string, hooks ...RuleParseHook) *Terminal {
 		Hooks: hooks,
 	}
 }
+
+// NewTerminalFold constructs a new *Terminal which
+// matches the input using unicode case-folding.
+func NewTerminalFold(name string, v string, hooks ...RuleParseHook) *Terminal {
+	return &Terminal{
+		name:  name,
+		Value: []byte(v),
+		Fold:  true,
+		Hooks: hooks,
+	}
+}
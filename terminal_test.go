@@ -101,6 +101,14 @@ func TestTerminalGetParameters(t *testing.T) {
 				"value": "hello",
 			},
 		},
+		{
+			NewTerminalFold("sample terminal", "hello"),
+			RuleParameters{
+				"name":  "sample terminal",
+				"value": "hello",
+				"fold":  true,
+			},
+		},
 	}
 	for k, sample := range samples {
 		msg := spew.Sdump(k, sample)
@@ -166,6 +174,16 @@ func TestTerminal(t *testing.T) {
 			),
 			DefaultParser,
 		},
+		{
+			"ba",
+			NewTerminal("foo", "foo"),
+			nil,
+			NewErrUnexpectedEOF(
+				NewTerminal("foo", "foo"),
+				&Location{Path: DefaultParserPath},
+			),
+			DefaultParser,
+		},
 		{
 			"oo",
 			NewTerminal("o", "oó"),
@@ -244,3 +262,58 @@ func TestTerminal(t *testing.T) {
 		})
 	}
 }
+
+func TestTerminalFold(t *testing.T) {
+	samples := []struct {
+		text string
+		rule Rule
+		data []byte
+		err  error
+	}{
+		{"select", NewTerminalFold("select", "SELECT"), []byte("select"), nil},
+		{"SeLeCt", NewTerminalFold("select", "select"), []byte("SeLeCt"), nil},
+		{"ПРИВЕТ", NewTerminalFold("hello", "привет"), []byte("ПРИВЕТ"), nil},
+		{"\u212a", NewTerminalFold("kelvin", "k"), []byte("\u212a"), nil},
+		{
+			"SEL",
+			NewTerminalFold("select", "select"),
+			nil,
+			NewErrUnexpectedEOF(
+				NewTerminalFold("select", "select"),
+				&Location{Path: DefaultParserPath},
+			),
+		},
+		{
+			"SELL",
+			NewTerminalFold("select", "select"),
+			nil,
+			NewErrUnexpectedToken(
+				NewTerminalFold("select", "select"),
+				&Location{Path: DefaultParserPath},
+				[]byte("SELL"),
+			),
+		},
+		{
+			"SELECT",
+			NewTerminal("select", "select"),
+			nil,
+			NewErrUnexpectedToken(
+				NewTerminal("select", "select"),
+				&Location{Path: DefaultParserPath},
+				[]byte("SELECT"),
+			),
+		},
+	}
+	for k, sample := range samples {
+		t.Run(fmt.Sprintf("%d", k), func(t *testing.T) {
+			msg := spew.Sdump(k, sample.text)
+			tree, err := Parse(sample.rule, []byte(sample.text))
+			assert.EqualValues(t, sample.err, err, msg)
+			if sample.err != nil {
+				return
+			}
+			assert.Equal(t, sample.data, tree.Data, msg)
+			assert.Equal(t, len(sample.data), tree.Region.End, msg)
+		})
+	}
+}
@@ -14,6 +14,23 @@ type Tree struct {
 	Depth    int
 	Childs   []*Tree
 	Data     []byte
+
+	// Absent is true when Tree represents a Rule
+	// which was not found in the input, see Optional.
+	Absent bool
+
+	// Value is a value decoded from Data by the Rule,
+	// for example unescaped string of StringLiteral.
+	Value interface{}
+
+	// action is true when Value was computed by Action.
+	action bool
+
+	// Err is set when Tree represents an input which was
+	// skipped by the error recovery, see Parser.Recover.
+	// Rule is the Rule which failed, Childs contains
+	// the match of the synchronization rule.
+	Err error
 }
 
 // Name returns current node name.
@@ -54,6 +71,17 @@ func (t *Tree) String() string {
 	return TreerString(t)
 }
 
+// Errors returns errors of all nodes skipped by the error recovery
+// (see Tree.Err) in order of appearance in the input.
+func (t *Tree) Errors() ErrorList {
+	errs := ErrorList{}
+	errs.Add(t.Err)
+	for _, child := range t.Childs {
+		errs.Add(child.Errors())
+	}
+	return errs
+}
+
 
 // Hash produces a lication which is believed
 // to uniquely identify the node in the tree.
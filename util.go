@@ -51,3 +51,10 @@ func indirectValue(reflectValue reflect.Value) reflect.Value {
 	}
 	return reflectValue
 }
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -4,10 +4,12 @@ var _ Rule = new(Wrapper)
 
 // Wrapper represents a wrapper type for some inner Rule.
 // It could be used to wrap a Rule with custom name.
+// Tree.Value of the inner Rule is passed to the Wrapper Tree.
 type Wrapper struct {
-	name  string
-	Rule  Rule
-	Hooks []RuleParseHook
+	name       string
+	Rule       Rule
+	Hooks      []RuleParseHook
+	EnterHooks []RuleEnterHook
 }
 
 // Name indicates the name which was given to the rule
@@ -61,6 +63,10 @@ func (r *Wrapper) IsFinite() bool {
 // May return an error if something goes wrong, should provide some
 // location information to the user which points to position in input.
 func (r *Wrapper) Parse(ctx *Context, input []byte) (*Tree, error) {
+	if err := runEnterHooks(ctx, r, input, r.EnterHooks); err != nil {
+		return nil, err
+	}
+
 	if r.Rule == nil {
 		return nil, NewErrEmptyRule(r, r.Rule)
 	}
@@ -79,7 +85,8 @@ func (r *Wrapper) Parse(ctx *Context, input []byte) (*Tree, error) {
 		line, col = ctx.Parser.Locate(ctx.Location.Position)
 	)
 
-	subTree, err = r.Rule.Parse(
+	subTree, err = ctx.Parser.ParseRule(
+		r.Rule,
 		&Context{
 			Rule:   r,
 			Parser: ctx.Parser,
@@ -90,6 +97,7 @@ func (r *Wrapper) Parse(ctx *Context, input []byte) (*Tree, error) {
 				Column:   col,
 			},
 			Depth: nextDepth,
+			State: ctx.State,
 		},
 		input,
 	)
@@ -111,9 +119,11 @@ func (r *Wrapper) Parse(ctx *Context, input []byte) (*Tree, error) {
 		Depth:  ctx.Depth,
 		Childs: []*Tree{subTree},
 		Data:   input[:region.End-region.Start],
+		Value:  subTree.Value,
+		action: subTree.action,
 	}
-	for _, hook := range r.Hooks {
-		hook(ctx, tree)
+	if err := runHooks(ctx, r, tree, r.Hooks); err != nil {
+		return nil, err
 	}
 	return tree, nil
 }